@@ -0,0 +1,50 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/mcosta74/hexkit/requests/auth"
+)
+
+// BearerTokenToContext returns a RequestFunc authenticating the bearer token of the
+// Authorization header with authn, e.g. the Authenticate method of an auth.JWTVerifier.
+// The principal, or the failure, is stored in the context for the auth middleware.
+func BearerTokenToContext(authn auth.TokenAuthenticator) RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !ok || !strings.EqualFold(scheme, "Bearer") {
+			return ctx
+		}
+		p, err := authn(ctx, strings.TrimSpace(token))
+		return auth.ContextWithAuthentication(ctx, auth.MethodJWT, p, err)
+	}
+}
+
+// APIKeyToContext returns a RequestFunc authenticating the API key found in header with authn.
+// The principal, or the failure, is stored in the context for the auth middleware.
+func APIKeyToContext(header string, authn auth.TokenAuthenticator) RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		key := r.Header.Get(header)
+		if key == "" {
+			return ctx
+		}
+		p, err := authn(ctx, key)
+		return auth.ContextWithAuthentication(ctx, auth.MethodAPIKey, p, err)
+	}
+}
+
+// BasicAuthToContext returns a RequestFunc authenticating the credentials of the
+// Basic Authorization header with authn.
+// The principal, or the failure, is stored in the context for the auth middleware.
+func BasicAuthToContext(authn auth.PasswordAuthenticator) RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		username, password, ok := r.BasicAuth()
+		if !ok {
+			return ctx
+		}
+		p, err := authn(ctx, username, password)
+		return auth.ContextWithAuthentication(ctx, auth.MethodBasic, p, err)
+	}
+}
@@ -0,0 +1,65 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	kithttp "github.com/mcosta74/hexkit/adapters/http"
+	"github.com/mcosta74/hexkit/requests"
+	"github.com/mcosta74/hexkit/requests/auth"
+)
+
+func TestAuth(t *testing.T) {
+	whoami := auth.New[struct{}, string]()(requests.HandlerFunc[struct{}, string](func(ctx context.Context, _ struct{}) (string, error) {
+		p, _ := auth.PrincipalFromContext(ctx)
+		return p.Method + ":" + p.Subject, nil
+	}))
+
+	tokens := func(_ context.Context, token string) (*auth.Principal, error) {
+		if token != "t1" {
+			return nil, auth.ErrInvalidToken
+		}
+		return &auth.Principal{Subject: "alice"}, nil
+	}
+
+	server := kithttp.NewServer(whoami,
+		kithttp.NoOpRequestDecoder[struct{}],
+		kithttp.EncodeJSONResponse[string],
+		kithttp.WithServerBefore[struct{}, string](
+			kithttp.BearerTokenToContext(tokens),
+			kithttp.APIKeyToContext("X-Api-Key", auth.StaticAPIKeys(map[string]string{"k1": "svc"})),
+			kithttp.BasicAuthToContext(auth.StaticUsers(map[string]string{"bob": "secret"})),
+		),
+	)
+
+	for _, tc := range []struct {
+		name   string
+		header func(h http.Header)
+		status int
+		body   string
+	}{
+		{"Bearer", func(h http.Header) { h.Set("Authorization", "Bearer t1") }, http.StatusOK, `"jwt:alice"`},
+		{"Invalid Bearer", func(h http.Header) { h.Set("Authorization", "bearer t2") }, http.StatusUnauthorized, "auth: invalid token"},
+		{"API Key", func(h http.Header) { h.Set("X-Api-Key", "k1") }, http.StatusOK, `"apikey:svc"`},
+		{"Basic", func(h http.Header) { h.Set("Authorization", "Basic Ym9iOnNlY3JldA==") }, http.StatusOK, `"basic:bob"`},
+		{"Invalid Basic", func(h http.Header) { h.Set("Authorization", "Basic Ym9iOndyb25n") }, http.StatusUnauthorized, "auth: invalid credentials"},
+		{"Anonymous", func(http.Header) {}, http.StatusUnauthorized, "auth: missing credentials"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			tc.header(r.Header)
+			w := httptest.NewRecorder()
+
+			server.ServeHTTP(w, r)
+
+			if w.Code != tc.status {
+				t.Errorf("unexpected status: want=%d, got=%d", tc.status, w.Code)
+			}
+			if got := w.Body.String(); got != tc.body && got != tc.body+"\n" {
+				t.Errorf("unexpected body: want=%s, got=%s", tc.body, got)
+			}
+		})
+	}
+}
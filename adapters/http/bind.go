@@ -0,0 +1,221 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/mcosta74/hexkit/internal/bind"
+)
+
+// Binding sources, as used in the struct tags understood by [BindRequest].
+const (
+	BindPath   = "path"
+	BindQuery  = "query"
+	BindHeader = "header"
+	BindCookie = "cookie"
+	BindForm   = "form"
+	BindBody   = "body"
+)
+
+var bindSources = []string{BindPath, BindQuery, BindHeader, BindCookie, BindForm}
+
+// FieldError reports a failure binding a single field of the request.
+type FieldError struct {
+	// Source is where the value comes from, e.g. "query" or "body".
+	Source string
+	// Name is the name of the parameter in the source.
+	Name string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("%s: %v", e.Source, e.Err)
+	}
+	return fmt.Sprintf("%s parameter %q: %v", e.Source, e.Name, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// BindingError aggregates the errors found binding a request.
+// It's reported by the default error encoders with http.StatusBadRequest.
+type BindingError struct {
+	Errors []*FieldError
+}
+
+func (e *BindingError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Error()
+	}
+	return "binding request: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the field errors.
+func (e *BindingError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, fe := range e.Errors {
+		errs[i] = fe
+	}
+	return errs
+}
+
+// StatusCode implements StatusCoder.
+func (e *BindingError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// ErrMissingValue is reported for required parameters which are not found in the request.
+var ErrMissingValue = errors.New("missing value")
+
+// BindRequest is a DecodeRequestFunc which populates a struct request from the HTTP request.
+//
+// The body is decoded first: as JSON when the content type is JSON, or as form values
+// bound to the `form` tags when the content type is a form. Then the fields tagged with
+// `path` (path values of the http.ServeMux patterns), `query`, `header` and `cookie`
+// are populated, taking precedence over the body. A tag may be followed by ",required".
+//
+//	type GetOrder struct {
+//		ID      int       `path:"id"`
+//		Fields  []string  `query:"fields"`
+//		Since   time.Time `query:"since"`
+//		TraceID string    `header:"X-Trace-Id"`
+//		Token   string    `cookie:"token,required"`
+//	}
+//
+// Supported field types are strings, booleans, integers, floats, time.Duration, types
+// implementing encoding.TextUnmarshaler (e.g. time.Time in RFC 3339 format), pointers
+// and slices of them. Embedded structs are traversed.
+//
+// All the failures are reported together in a [*BindingError].
+func BindRequest[Req any](_ context.Context, r *http.Request) (Req, error) {
+	var req Req
+
+	v := reflect.ValueOf(&req).Elem()
+	if v.Kind() != reflect.Struct {
+		return req, fmt.Errorf("binding request: %s is not a struct", v.Type())
+	}
+
+	var errs []*FieldError
+	errs = append(errs, bindBody(r, &req)...)
+
+	for _, f := range bindFieldsOf(v.Type()) {
+		values := f.lookup(r)
+		if len(values) == 0 {
+			if f.required {
+				errs = append(errs, &FieldError{Source: f.source, Name: f.name, Err: ErrMissingValue})
+			}
+			continue
+		}
+
+		if err := bind.SetField(v.FieldByIndex(f.index), values); err != nil {
+			errs = append(errs, &FieldError{Source: f.source, Name: f.name, Err: err})
+		}
+	}
+
+	if len(errs) > 0 {
+		return req, &BindingError{Errors: errs}
+	}
+	return req, nil
+}
+
+func bindBody(r *http.Request, req any) []*FieldError {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil && !errors.Is(err, io.EOF) {
+			return []*FieldError{{Source: BindBody, Err: err}}
+		}
+	case mediaType == "application/x-www-form-urlencoded":
+		if err := r.ParseForm(); err != nil {
+			return []*FieldError{{Source: BindBody, Err: err}}
+		}
+	case mediaType == "multipart/form-data":
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return []*FieldError{{Source: BindBody, Err: err}}
+		}
+	}
+	return nil
+}
+
+type bindField struct {
+	index    []int
+	source   string
+	name     string
+	required bool
+}
+
+func (f bindField) lookup(r *http.Request) []string {
+	switch f.source {
+	case BindPath:
+		if v := r.PathValue(f.name); v != "" {
+			return []string{v}
+		}
+	case BindQuery:
+		return r.URL.Query()[f.name]
+	case BindHeader:
+		return r.Header.Values(f.name)
+	case BindCookie:
+		if c, err := r.Cookie(f.name); err == nil {
+			return []string{c.Value}
+		}
+	case BindForm:
+		return r.PostForm[f.name]
+	}
+	return nil
+}
+
+var bindFieldsCache sync.Map
+
+// bindFieldsOf returns the tagged fields of the struct type t.
+func bindFieldsOf(t reflect.Type) []bindField {
+	if fields, ok := bindFieldsCache.Load(t); ok {
+		return fields.([]bindField)
+	}
+
+	var fields []bindField
+	for _, sf := range reflect.VisibleFields(t) {
+		if !sf.IsExported() || (sf.Anonymous && sf.Type.Kind() == reflect.Struct) {
+			continue
+		}
+		if bind.HasPointerParent(t, sf.Index) {
+			continue
+		}
+
+		for _, source := range bindSources {
+			tag, ok := sf.Tag.Lookup(source)
+			if !ok || tag == "-" {
+				continue
+			}
+
+			name, opts, _ := strings.Cut(tag, ",")
+			if name == "" {
+				name = sf.Name
+			}
+			fields = append(fields, bindField{
+				index:    sf.Index,
+				source:   source,
+				name:     name,
+				required: opts == "required",
+			})
+		}
+	}
+
+	bindFieldsCache.Store(t, fields)
+	return fields
+}
@@ -0,0 +1,156 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	kithttp "github.com/mcosta74/hexkit/adapters/http"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+type level int
+
+func (l *level) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return errors.New("invalid level")
+	}
+	return nil
+}
+
+type Paging struct {
+	Page  int  `query:"page"`
+	Limit *int `query:"limit"`
+}
+
+type bindRequest struct {
+	Paging
+	ID      int           `path:"id,required"`
+	Tags    []string      `query:"tag"`
+	Verbose bool          `query:"verbose"`
+	Since   time.Time     `query:"since"`
+	Timeout time.Duration `query:"timeout"`
+	Level   level         `query:"level"`
+	TraceID string        `header:"X-Trace-Id"`
+	Session string        `cookie:"session"`
+	Name    string        `json:"name" form:"name"`
+	Score   float64       `json:"score" form:"score"`
+}
+
+func serveBinding(t *testing.T, r *http.Request) (bindRequest, error) {
+	t.Helper()
+
+	var (
+		got    bindRequest
+		gotErr error
+	)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orders/{id}", func(w http.ResponseWriter, r *http.Request) {
+		got, gotErr = kithttp.BindRequest[bindRequest](r.Context(), r)
+	})
+	mux.ServeHTTP(httptest.NewRecorder(), r)
+	return got, gotErr
+}
+
+func TestBindRequest(t *testing.T) {
+	t.Run("All Sources", func(t *testing.T) {
+		r := httptest.NewRequest(
+			http.MethodPost,
+			"/orders/42?page=3&limit=10&tag=a&tag=b&verbose=true&since=2024-01-02T03:04:05Z&timeout=1m&level=high",
+			strings.NewReader(`{"name":"widget","score":1.5}`),
+		)
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("X-Trace-Id", "trace")
+		r.AddCookie(&http.Cookie{Name: "session", Value: "s3cr3t"})
+
+		got, err := serveBinding(t, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		limit := 10
+		want := bindRequest{
+			Paging:  Paging{Page: 3, Limit: &limit},
+			ID:      42,
+			Tags:    []string{"a", "b"},
+			Verbose: true,
+			Since:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			Timeout: time.Minute,
+			Level:   2,
+			TraceID: "trace",
+			Session: "s3cr3t",
+			Name:    "widget",
+			Score:   1.5,
+		}
+
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("unexpected request:\nwant=%+v\ngot= %+v", want, got)
+		}
+	})
+
+	t.Run("Form", func(t *testing.T) {
+		form := url.Values{"name": {"widget"}, "score": {"2.5"}}
+		r := httptest.NewRequest(http.MethodPost, "/orders/1", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		got, err := serveBinding(t, r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Name != "widget" || got.Score != 2.5 {
+			t.Errorf("unexpected request: %+v", got)
+		}
+	})
+
+	t.Run("Aggregated Errors", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/orders/x?page=one&level=medium", nil)
+
+		_, err := serveBinding(t, r)
+
+		var be *kithttp.BindingError
+		if !errors.As(err, &be) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if want, got := 3, len(be.Errors); want != got {
+			t.Fatalf("unexpected number of errors: want=%d, got=%d (%v)", want, got, err)
+		}
+		if want, got := `path parameter "id": strconv.ParseInt: parsing "x": invalid syntax`, be.Errors[1].Error(); want != got {
+			t.Errorf("unexpected error: want=%q, got=%q", want, got)
+		}
+	})
+
+	t.Run("Required", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/orders/1", nil)
+		r.SetPathValue("id", "")
+
+		_, err := kithttp.BindRequest[bindRequest](context.Background(), r)
+		if !errors.Is(err, kithttp.ErrMissingValue) {
+			t.Errorf("unexpected error: want=%v, got=%v", kithttp.ErrMissingValue, err)
+		}
+	})
+
+	t.Run("Status Code", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.Handle("/orders/{id}", kithttp.NewServer(
+			requests.HandlerFunc[bindRequest, struct{}](func(context.Context, bindRequest) (struct{}, error) { return struct{}{}, nil }),
+			kithttp.BindRequest[bindRequest],
+			kithttp.EncodeJSONResponse[struct{}],
+		))
+		server := httptest.NewServer(mux)
+		defer server.Close()
+
+		resp, _ := http.Get(server.URL + "/orders/x")
+		checkResponse(t, resp, http.StatusBadRequest, []byte(`binding request: path parameter "id": strconv.ParseInt: parsing "x": invalid syntax`))
+	})
+}
@@ -2,6 +2,7 @@ package http
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 )
 
@@ -10,3 +11,9 @@ type DecodeRequestFunc[Req any] func(ctx context.Context, r *http.Request) (requ
 
 // EncodeResponseFunc encodes the provided response object to the HTTP response writer.
 type EncodeResponseFunc[Resp any] func(ctx context.Context, rw http.ResponseWriter, resp Resp) error
+
+// EncodeJSONResponse is an EncodeResponseFunc that serializes the response as JSON.
+func EncodeJSONResponse[Resp any](_ context.Context, w http.ResponseWriter, resp Resp) error {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	return json.NewEncoder(w).Encode(resp)
+}
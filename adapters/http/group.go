@@ -0,0 +1,250 @@
+package http
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/mcosta74/hexkit/adapters"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+// MethodNotAllowedError is reported by a [Group] when a path is requested with a method
+// none of its routes accepts.
+type MethodNotAllowedError struct {
+	Method  string
+	Allowed []string
+}
+
+func (e *MethodNotAllowedError) Error() string {
+	return fmt.Sprintf("method %s not allowed", e.Method)
+}
+
+// StatusCode implements StatusCoder.
+func (e *MethodNotAllowedError) StatusCode() int {
+	return http.StatusMethodNotAllowed
+}
+
+// Group registers servers on a http.ServeMux under a common path prefix,
+// applying shared options to all of them.
+//
+// A group is also a http.Handler serving the requests with its mux. When it's the one
+// being served, it answers OPTIONS requests with the list of the methods allowed on the
+// requested path, and the requests with a method none of the matching routes accepts
+// with http.StatusMethodNotAllowed, encoded by its error encoder. The allowed methods are
+// the ones of the routes of the group the mux would route the request to. HEAD requests
+// are served by the GET routes.
+type Group struct {
+	routes       *groupRoutes
+	prefix       string
+	errorEncoder ErrorEncoder
+	errorHandler adapters.ErrorHandler
+	before       []RequestFunc
+	after        []ServerResponseFunc
+}
+
+// groupRoutes keeps track of the routes registered on a mux, shared by the group and its subgroups.
+type groupRoutes struct {
+	mux     *http.ServeMux
+	mu      sync.Mutex
+	methods []string
+	routes  []Route
+}
+
+// GroupOption sets optional parameters shared by the servers of a group.
+type GroupOption func(g *Group)
+
+// WithGroupErrorEncoder sets the error encoder for the servers of the group.
+func WithGroupErrorEncoder(ee ErrorEncoder) GroupOption {
+	return func(g *Group) {
+		g.errorEncoder = ee
+	}
+}
+
+// WithGroupErrorHandler sets the error handler for the servers of the group.
+func WithGroupErrorHandler(eh adapters.ErrorHandler) GroupOption {
+	return func(g *Group) {
+		g.errorHandler = eh
+	}
+}
+
+// WithGroupErrorLogger sets an error handler for the servers of the group that logs errors.
+func WithGroupErrorLogger(logger *slog.Logger) GroupOption {
+	return func(g *Group) {
+		g.errorHandler = adapters.NewSlogErrorHandler(logger)
+	}
+}
+
+// WithGroupBefore adds functions executed by the servers of the group
+// before their own before functions.
+func WithGroupBefore(before ...RequestFunc) GroupOption {
+	return func(g *Group) {
+		g.before = append(g.before, before...)
+	}
+}
+
+// WithGroupAfter adds functions executed by the servers of the group
+// before their own after functions.
+func WithGroupAfter(after ...ServerResponseFunc) GroupOption {
+	return func(g *Group) {
+		g.after = append(g.after, after...)
+	}
+}
+
+// NewGroup creates a group which registers its servers on mux under prefix.
+func NewGroup(mux *http.ServeMux, prefix string, options ...GroupOption) *Group {
+	g := &Group{
+		routes:       &groupRoutes{mux: mux},
+		prefix:       strings.TrimSuffix(prefix, "/"),
+		errorEncoder: DefaultErrorEncoder,
+		errorHandler: adapters.NewNoOpErrorHandler(),
+	}
+
+	for _, o := range options {
+		o(g)
+	}
+	return g
+}
+
+// Group creates a subgroup with the given prefix appended to the one of g.
+// The subgroup inherits the options of g; options adds to them.
+func (g *Group) Group(prefix string, options ...GroupOption) *Group {
+	sub := &Group{
+		routes:       g.routes,
+		prefix:       g.prefix + strings.TrimSuffix(prefix, "/"),
+		errorEncoder: g.errorEncoder,
+		errorHandler: g.errorHandler,
+		before:       slices.Clone(g.before),
+		after:        slices.Clone(g.after),
+	}
+
+	for _, o := range options {
+		o(sub)
+	}
+	return sub
+}
+
+// Handle creates a [Server] with the shared options of g followed by options and registers it
+// on the mux of g. pattern is in the form "METHOD /path", where path may contain the wildcards
+// supported by http.ServeMux; it's relative to the prefix of the group.
+func Handle[Req, Resp any](
+	g *Group,
+	pattern string,
+	h requests.Handler[Req, Resp],
+	dec DecodeRequestFunc[Req],
+	enc EncodeResponseFunc[Resp],
+	options ...ServerOption[Req, Resp],
+) *Server[Req, Resp] {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok || method == "" || !strings.HasPrefix(path, "/") {
+		panic(fmt.Sprintf("http: invalid pattern %q: want \"METHOD /path\"", pattern))
+	}
+	path = g.prefix + path
+
+	opts := []ServerOption[Req, Resp]{
+		WithErrorEncoder[Req, Resp](g.errorEncoder),
+		WithErrorHandler[Req, Resp](g.errorHandler),
+		WithServerBefore[Req, Resp](g.before...),
+		WithServerAfter[Req, Resp](g.after...),
+	}
+	s := NewServer(h, dec, enc, append(opts, options...)...)
+
+	g.routes.register(Route{
+		Method:    method,
+		Path:      path,
+		Request:   reflect.TypeFor[Req](),
+		Response:  reflect.TypeFor[Resp](),
+		Operation: s.op,
+	}, s)
+	return s
+}
+
+// Routes returns the routes registered on the mux of g by g, its parent and its subgroups,
+// in registration order.
+func (g *Group) Routes() []Route {
+	g.routes.mu.Lock()
+	defer g.routes.mu.Unlock()
+
+	return slices.Clone(g.routes.routes)
+}
+
+// ServeHTTP implements http.Handler.
+func (g *Group) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, pattern := g.routes.mux.Handler(r); pattern != "" || !isCleanPath(r) {
+		g.routes.mux.ServeHTTP(w, r)
+		return
+	}
+
+	allowed := g.routes.allowedMethods(r)
+	if len(allowed) == 0 {
+		g.routes.mux.ServeHTTP(w, r)
+		return
+	}
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	g.errorEncoder(r.Context(), &MethodNotAllowedError{Method: r.Method, Allowed: allowed}, w)
+}
+
+func (rs *groupRoutes) register(route Route, h http.Handler) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	rs.mux.Handle(route.Method+" "+route.Path, h)
+	rs.routes = append(rs.routes, route)
+
+	methods := []string{route.Method}
+	if route.Method == http.MethodGet {
+		methods = append(methods, http.MethodHead)
+	}
+	for _, m := range methods {
+		if !slices.Contains(rs.methods, m) {
+			rs.methods = append(rs.methods, m)
+		}
+	}
+}
+
+// allowedMethods returns the sorted methods of the routes matching the path of r,
+// including OPTIONS. It returns nil if none matches.
+func (rs *groupRoutes) allowedMethods(r *http.Request) []string {
+	rs.mu.Lock()
+	methods := slices.Clone(rs.methods)
+	rs.mu.Unlock()
+
+	var allowed []string
+	probe := *r
+	for _, m := range methods {
+		probe.Method = m
+		if _, pattern := rs.mux.Handler(&probe); pattern != "" {
+			allowed = append(allowed, m)
+		}
+	}
+	if len(allowed) == 0 {
+		return nil
+	}
+	allowed = append(allowed, http.MethodOptions)
+	slices.Sort(allowed)
+	return slices.Compact(allowed)
+}
+
+// isCleanPath reports whether the path of r is the one the mux would route it to,
+// rather than redirecting it.
+func isCleanPath(r *http.Request) bool {
+	if r.Method == http.MethodConnect {
+		return true
+	}
+	p := r.URL.EscapedPath()
+	clean := path.Clean(p)
+	if strings.HasSuffix(p, "/") && clean != "/" {
+		clean += "/"
+	}
+	return p == clean
+}
@@ -0,0 +1,184 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	kithttp "github.com/mcosta74/hexkit/adapters/http"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+func TestGroup(t *testing.T) {
+	type getOrder struct {
+		ID string `path:"id"`
+	}
+
+	var events []string
+	mux := http.NewServeMux()
+	g := kithttp.NewGroup(mux, "/api/",
+		kithttp.WithGroupErrorEncoder(kithttp.JSONErrorEncoder),
+		kithttp.WithGroupBefore(func(ctx context.Context, _ *http.Request) context.Context {
+			events = append(events, "group")
+			return ctx
+		}),
+	)
+	v1 := g.Group("/v1")
+
+	kithttp.Handle(v1, "GET /orders/{id}",
+		requests.HandlerFunc[getOrder, string](func(_ context.Context, req getOrder) (string, error) { return "order " + req.ID, nil }),
+		kithttp.BindRequest[getOrder],
+		kithttp.EncodeJSONResponse[string],
+		kithttp.WithServerBefore[getOrder, string](func(ctx context.Context, _ *http.Request) context.Context {
+			events = append(events, "route")
+			return ctx
+		}),
+	)
+	kithttp.Handle(v1, "DELETE /orders/{id}",
+		requests.HandlerFunc[getOrder, struct{}](func(context.Context, getOrder) (struct{}, error) { return struct{}{}, nil }),
+		kithttp.BindRequest[getOrder],
+		func(_ context.Context, w http.ResponseWriter, _ struct{}) error {
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		},
+	)
+
+	server := httptest.NewServer(g)
+	defer server.Close()
+
+	do := func(method string) *http.Response {
+		req, _ := http.NewRequest(method, server.URL+"/api/v1/orders/7", nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	t.Run("Route", func(t *testing.T) {
+		events = nil
+		checkResponse(t, do(http.MethodGet), http.StatusOK, []byte("\"order 7\"\n"))
+
+		if want, got := "group,route", strings.Join(events, ","); want != got {
+			t.Errorf("unexpected before order: want=%q, got=%q", want, got)
+		}
+	})
+
+	t.Run("Head", func(t *testing.T) {
+		checkResponse(t, do(http.MethodHead), http.StatusOK, nil)
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		resp := do(http.MethodOptions)
+		if want, got := "DELETE, GET, HEAD, OPTIONS", resp.Header.Get("Allow"); want != got {
+			t.Errorf("unexpected Allow header: want=%q, got=%q", want, got)
+		}
+		checkResponse(t, resp, http.StatusNoContent, nil)
+	})
+
+	t.Run("Method Not Allowed", func(t *testing.T) {
+		resp := do(http.MethodPut)
+		if want, got := "DELETE, GET, HEAD, OPTIONS", resp.Header.Get("Allow"); want != got {
+			t.Errorf("unexpected Allow header: want=%q, got=%q", want, got)
+		}
+		checkResponse(t, resp, http.StatusMethodNotAllowed, []byte(`{"err":"method PUT not allowed"}`))
+	})
+
+	t.Run("Wildcard Names", func(t *testing.T) {
+		mux := http.NewServeMux()
+		g := kithttp.NewGroup(mux, "/", kithttp.WithGroupErrorEncoder(kithttp.JSONErrorEncoder))
+		sub := g.Group("/sub", kithttp.WithGroupErrorEncoder(kithttp.DefaultErrorEncoder))
+		for _, pattern := range []string{"GET /items/{id}", "DELETE /items/{name}"} {
+			kithttp.Handle(sub, pattern,
+				requests.HandlerFunc[struct{}, struct{}](func(context.Context, struct{}) (struct{}, error) { return struct{}{}, nil }),
+				kithttp.NoOpRequestDecoder[struct{}],
+				kithttp.EncodeJSONResponse[struct{}],
+			)
+		}
+
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/sub/items/7", nil))
+		if want, got := "DELETE, GET, HEAD, OPTIONS", w.Header().Get("Allow"); want != got {
+			t.Errorf("unexpected Allow header: want=%q, got=%q", want, got)
+		}
+		// encoded by the served group
+		checkResponse(t, w.Result(), http.StatusMethodNotAllowed, []byte(`{"err":"method PUT not allowed"}`))
+	})
+
+	t.Run("Prefix Path", func(t *testing.T) {
+		mux := http.NewServeMux()
+		g := kithttp.NewGroup(mux, "/", kithttp.WithGroupErrorEncoder(kithttp.JSONErrorEncoder))
+		kithttp.Handle(g, "GET /files/",
+			requests.HandlerFunc[struct{}, struct{}](func(context.Context, struct{}) (struct{}, error) { return struct{}{}, nil }),
+			kithttp.NoOpRequestDecoder[struct{}],
+			kithttp.EncodeJSONResponse[struct{}],
+		)
+		kithttp.Handle(g, "POST /files/{name}",
+			requests.HandlerFunc[struct{}, struct{}](func(context.Context, struct{}) (struct{}, error) { return struct{}{}, nil }),
+			kithttp.NoOpRequestDecoder[struct{}],
+			kithttp.EncodeJSONResponse[struct{}],
+		)
+
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/files/a/b", nil))
+		if want, got := "GET, HEAD, OPTIONS", w.Header().Get("Allow"); want != got {
+			t.Errorf("unexpected Allow header: want=%q, got=%q", want, got)
+		}
+		checkResponse(t, w.Result(), http.StatusMethodNotAllowed, []byte(`{"err":"method DELETE not allowed"}`))
+	})
+
+	t.Run("Overlapping Paths", func(t *testing.T) {
+		for _, patterns := range [][]string{
+			{"GET /items/{id}", "POST /items/new", "GET /items/new"},
+			{"GET /items/new", "POST /items/new", "GET /items/{id}"},
+		} {
+			mux := http.NewServeMux()
+			g := kithttp.NewGroup(mux, "/")
+			for _, pattern := range patterns {
+				kithttp.Handle(g, pattern,
+					requests.HandlerFunc[struct{}, struct{}](func(context.Context, struct{}) (struct{}, error) { return struct{}{}, nil }),
+					kithttp.NoOpRequestDecoder[struct{}],
+					kithttp.EncodeJSONResponse[struct{}],
+				)
+			}
+
+			for path, want := range map[string]string{
+				"/items/7":   "GET, HEAD, OPTIONS",
+				"/items/new": "GET, HEAD, OPTIONS, POST",
+			} {
+				w := httptest.NewRecorder()
+				g.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, path, nil))
+				if got := w.Header().Get("Allow"); want != got {
+					t.Errorf("%v: unexpected Allow header for %s: want=%q, got=%q", patterns, path, want, got)
+				}
+				checkResponse(t, w.Result(), http.StatusNoContent, nil)
+			}
+		}
+	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		g.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/api/v1/customers/7", nil))
+		if got := w.Header().Get("Allow"); got != "" {
+			t.Errorf("unexpected Allow header: %q", got)
+		}
+		if want, got := http.StatusNotFound, w.Code; want != got {
+			t.Errorf("unexpected status code: want=%d, got=%d", want, got)
+		}
+	})
+
+	t.Run("Invalid Pattern", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic")
+			}
+		}()
+		kithttp.Handle(g, "/orders",
+			requests.HandlerFunc[struct{}, struct{}](func(context.Context, struct{}) (struct{}, error) { return struct{}{}, nil }),
+			kithttp.NoOpRequestDecoder[struct{}],
+			kithttp.EncodeJSONResponse[struct{}],
+		)
+	})
+}
@@ -0,0 +1,8 @@
+// Package openapi generates OpenAPI 3.1 documents from the routes registered on a Group
+// of the HTTP adapter.
+//
+// Request and response schemas are derived by reflection from the types of the servers:
+// the `json` tags name the properties, the `validate` tags add constraints and the `doc`
+// tags add descriptions. Request fields bound with the `path`, `query`, `header` and
+// `cookie` tags of BindRequest are documented as parameters.
+package openapi
@@ -0,0 +1,225 @@
+package openapi
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	kithttp "github.com/mcosta74/hexkit/adapters/http"
+	"github.com/mcosta74/hexkit/internal/jsonschema"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+// Option sets optional parameters for the document generation.
+type Option func(c *config)
+
+type config struct {
+	errorType reflect.Type
+}
+
+// WithErrorType sets the type of the error responses body.
+// The default is the body written by JSONErrorEncoder.
+func WithErrorType[T any]() Option {
+	return func(c *config) {
+		c.errorType = reflect.TypeFor[T]()
+	}
+}
+
+// ErrorBody is the body of the errors encoded by JSONErrorEncoder.
+type ErrorBody struct {
+	Err string `json:"err,omitempty" doc:"error message"`
+}
+
+var (
+	paramSources = []string{kithttp.BindPath, kithttp.BindQuery, kithttp.BindHeader, kithttp.BindCookie}
+	// nonBodySources are the binding sources of the fields excluded from the request body.
+	nonBodySources = append(paramSources, kithttp.BindForm)
+)
+
+// Generate builds the document describing routes.
+func Generate(info Info, routes []kithttp.Route, options ...Option) *Document {
+	cfg := config{
+		errorType: reflect.TypeFor[ErrorBody](),
+	}
+	for _, o := range options {
+		o(&cfg)
+	}
+
+	gen := jsonschema.NewGenerator("#/components/schemas/")
+	gen.SkipField = func(sf reflect.StructField) bool {
+		for _, source := range nonBodySources {
+			if _, ok := sf.Tag.Lookup(source); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]PathItem),
+	}
+
+	errorSchema := gen.Schema(cfg.errorType)
+	for _, route := range routes {
+		if route.Operation.Hidden {
+			continue
+		}
+
+		path, pathParams := convertPath(route.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = make(PathItem)
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = operation(gen, route, pathParams, errorSchema)
+	}
+
+	if defs := gen.Definitions(); len(defs) > 0 {
+		doc.Components = &Components{Schemas: defs}
+	}
+	return doc
+}
+
+// Mount registers on g a route serving, on "GET path", the document describing all the routes
+// registered on the mux of g. The document is generated on each request.
+func Mount(g *kithttp.Group, path string, info Info, options ...Option) {
+	kithttp.Handle(g, http.MethodGet+" "+path,
+		requests.HandlerFunc[struct{}, *Document](func(context.Context, struct{}) (*Document, error) {
+			return Generate(info, g.Routes(), options...), nil
+		}),
+		kithttp.NoOpRequestDecoder[struct{}],
+		kithttp.EncodeJSONResponse[*Document],
+		kithttp.WithOperation[struct{}, *Document](kithttp.Operation{Hidden: true}),
+	)
+}
+
+var wildcardRe = regexp.MustCompile(`\{([^}.$]+)(\.\.\.)?\}`)
+
+// convertPath turns a http.ServeMux pattern path into an OpenAPI path template
+// and returns the names of its parameters.
+func convertPath(pattern string) (string, []string) {
+	var names []string
+	path := wildcardRe.ReplaceAllStringFunc(pattern, func(m string) string {
+		name := wildcardRe.FindStringSubmatch(m)[1]
+		names = append(names, name)
+		return "{" + name + "}"
+	})
+	return strings.ReplaceAll(path, "{$}", ""), names
+}
+
+func operation(gen *jsonschema.Generator, route kithttp.Route, pathParams []string, errorSchema *Schema) *Operation {
+	op := &Operation{
+		OperationID: route.Operation.ID,
+		Summary:     route.Operation.Summary,
+		Description: route.Operation.Description,
+		Tags:        route.Operation.Tags,
+		Deprecated:  route.Operation.Deprecated,
+		Parameters:  parameters(gen, route.Request, pathParams),
+		Responses:   make(map[string]*Response),
+	}
+
+	if hasBody(route.Method) {
+		if schema := bodySchema(gen, route.Request); schema != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  jsonContent(schema),
+			}
+		}
+	}
+
+	success := &Response{Description: "Successful response"}
+	if schema := bodySchema(gen, route.Response); schema != nil {
+		success.Content = jsonContent(schema)
+	}
+	op.Responses[strconv.Itoa(http.StatusOK)] = success
+
+	for code, desc := range route.Operation.Errors {
+		op.Responses[strconv.Itoa(code)] = &Response{Description: desc, Content: jsonContent(errorSchema)}
+	}
+	op.Responses["default"] = &Response{Description: "Unexpected error", Content: jsonContent(errorSchema)}
+
+	return op
+}
+
+func parameters(gen *jsonschema.Generator, t reflect.Type, pathParams []string) []*Parameter {
+	var params []*Parameter
+	declared := make(map[string]bool)
+
+	if t = indirect(t); t.Kind() == reflect.Struct {
+		for _, sf := range reflect.VisibleFields(t) {
+			if !sf.IsExported() || (sf.Anonymous && sf.Type.Kind() == reflect.Struct) {
+				continue
+			}
+
+			for _, source := range paramSources {
+				tag, ok := sf.Tag.Lookup(source)
+				if !ok || tag == "-" {
+					continue
+				}
+				name, opts, _ := strings.Cut(tag, ",")
+				if name == "" {
+					name = sf.Name
+				}
+
+				schema := gen.Schema(sf.Type)
+				required := jsonschema.ApplyValidateTag(schema, gen.Resolve(schema), sf.Tag.Get("validate"))
+				p := &Parameter{
+					Name:        name,
+					In:          source,
+					Description: sf.Tag.Get("doc"),
+					Required:    required || opts == "required" || source == kithttp.BindPath,
+					Schema:      schema,
+				}
+				params = append(params, p)
+				declared[source+":"+name] = true
+			}
+		}
+	}
+
+	for _, name := range pathParams {
+		if !declared[kithttp.BindPath+":"+name] {
+			params = append(params, &Parameter{
+				Name:     name,
+				In:       kithttp.BindPath,
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+	}
+	return params
+}
+
+// bodySchema returns the schema of the body of t, or nil if there is no body.
+func bodySchema(gen *jsonschema.Generator, t reflect.Type) *Schema {
+	schema := gen.Schema(t)
+	if resolved := gen.Resolve(schema); resolved.Type == "object" && len(resolved.Properties) == 0 && resolved.AdditionalProperties == nil {
+		return nil
+	}
+	return schema
+}
+
+func jsonContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{
+		"application/json": {Schema: schema},
+	}
+}
+
+func hasBody(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,167 @@
+package openapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	kithttp "github.com/mcosta74/hexkit/adapters/http"
+	"github.com/mcosta74/hexkit/adapters/http/openapi"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+type Order struct {
+	ID   string `json:"id"`
+	Item string `json:"item" validate:"required" doc:"item name"`
+}
+
+type getOrder struct {
+	ID     string   `path:"id" validate:"uuid"`
+	Fields []string `query:"fields"`
+	Trace  string   `header:"X-Trace-Id,required"`
+}
+
+type createOrder struct {
+	Tenant string `path:"tenant"`
+	Item   string `json:"item" validate:"required"`
+}
+
+func newGroup() (*http.ServeMux, *kithttp.Group) {
+	mux := http.NewServeMux()
+	g := kithttp.NewGroup(mux, "/api")
+
+	kithttp.Handle(g, "GET /orders/{id}",
+		requests.HandlerFunc[getOrder, Order](func(_ context.Context, req getOrder) (Order, error) { return Order{ID: req.ID}, nil }),
+		kithttp.BindRequest[getOrder],
+		kithttp.EncodeJSONResponse[Order],
+		kithttp.WithOperation[getOrder, Order](kithttp.Operation{
+			ID:     "getOrder",
+			Tags:   []string{"orders"},
+			Errors: map[int]string{http.StatusNotFound: "Order not found"},
+		}),
+	)
+	kithttp.Handle(g, "POST /tenants/{tenant}/orders/{$}",
+		requests.HandlerFunc[createOrder, Order](func(_ context.Context, req createOrder) (Order, error) { return Order{Item: req.Item}, nil }),
+		kithttp.BindRequest[createOrder],
+		kithttp.EncodeJSONResponse[Order],
+	)
+	kithttp.Handle(g, "DELETE /files/{path...}",
+		requests.HandlerFunc[struct{}, struct{}](func(context.Context, struct{}) (struct{}, error) { return struct{}{}, nil }),
+		kithttp.NoOpRequestDecoder[struct{}],
+		kithttp.EncodeJSONResponse[struct{}],
+	)
+	return mux, g
+}
+
+func TestGenerate(t *testing.T) {
+	_, g := newGroup()
+
+	doc := openapi.Generate(openapi.Info{Title: "Orders", Version: "1.0"}, g.Routes())
+
+	if want, got := openapi.Version, doc.OpenAPI; want != got {
+		t.Errorf("unexpected version: want=%q, got=%q", want, got)
+	}
+
+	t.Run("Parameters", func(t *testing.T) {
+		op := doc.Paths["/api/orders/{id}"]["get"]
+		if op == nil {
+			t.Fatalf("missing operation, paths: %v", doc.Paths)
+		}
+		if want, got := "getOrder", op.OperationID; want != got {
+			t.Errorf("unexpected operation id: want=%q, got=%q", want, got)
+		}
+		if op.RequestBody != nil {
+			t.Error("unexpected request body")
+		}
+
+		b, _ := json.Marshal(op.Parameters)
+		want := `[{"name":"id","in":"path","required":true,"schema":{"type":"string","format":"uuid"}},` +
+			`{"name":"fields","in":"query","schema":{"type":"array","items":{"type":"string"}}},` +
+			`{"name":"X-Trace-Id","in":"header","required":true,"schema":{"type":"string"}}]`
+		if want != string(b) {
+			t.Errorf("unexpected parameters:\nwant=%s\ngot= %s", want, b)
+		}
+	})
+
+	t.Run("Responses", func(t *testing.T) {
+		op := doc.Paths["/api/orders/{id}"]["get"]
+		for _, code := range []string{"200", "404", "default"} {
+			if _, ok := op.Responses[code]; !ok {
+				t.Errorf("missing response %s", code)
+			}
+		}
+		if want, got := "#/components/schemas/Order", op.Responses["200"].Content["application/json"].Schema.Ref; want != got {
+			t.Errorf("unexpected response schema: want=%q, got=%q", want, got)
+		}
+		if want, got := "#/components/schemas/ErrorBody", op.Responses["404"].Content["application/json"].Schema.Ref; want != got {
+			t.Errorf("unexpected error schema: want=%q, got=%q", want, got)
+		}
+
+		del := doc.Paths["/api/files/{path}"]["delete"]
+		if del == nil {
+			t.Fatalf("missing operation, paths: %v", doc.Paths)
+		}
+		if del.Responses["200"].Content != nil {
+			t.Error("unexpected content for empty response")
+		}
+	})
+
+	t.Run("Request Body", func(t *testing.T) {
+		op := doc.Paths["/api/tenants/{tenant}/orders/"]["post"]
+		if op == nil || op.RequestBody == nil {
+			t.Fatalf("missing request body, paths: %v", doc.Paths)
+		}
+
+		b, _ := json.Marshal(op.RequestBody.Content["application/json"].Schema)
+		if want := `{"$ref":"#/components/schemas/createOrder"}`; want != string(b) {
+			t.Errorf("unexpected body schema:\nwant=%s\ngot= %s", want, b)
+		}
+
+		b, _ = json.Marshal(doc.Components.Schemas["createOrder"])
+		if want := `{"type":"object","properties":{"item":{"type":"string"}},"required":["item"]}`; want != string(b) {
+			t.Errorf("unexpected body definition:\nwant=%s\ngot= %s", want, b)
+		}
+	})
+
+	t.Run("Error Type", func(t *testing.T) {
+		type problem struct {
+			Title string `json:"title"`
+		}
+
+		doc := openapi.Generate(openapi.Info{}, g.Routes(), openapi.WithErrorType[problem]())
+		if _, ok := doc.Components.Schemas["problem"]; !ok {
+			t.Errorf("missing error schema, schemas: %v", doc.Components.Schemas)
+		}
+	})
+}
+
+func TestMount(t *testing.T) {
+	mux, g := newGroup()
+	openapi.Mount(g, "/openapi.json", openapi.Info{Title: "Orders", Version: "1.0"})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/openapi.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if want, got := http.StatusOK, resp.StatusCode; want != got {
+		t.Fatalf("unexpected status code: want=%d, got=%d", want, got)
+	}
+
+	var doc openapi.Document
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := 3, len(doc.Paths); want != got {
+		t.Errorf("unexpected number of paths: want=%d, got=%d", want, got)
+	}
+	if _, ok := doc.Paths["/api/openapi.json"]; ok {
+		t.Error("unexpected documented spec endpoint")
+	}
+}
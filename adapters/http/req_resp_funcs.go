@@ -3,8 +3,13 @@ package http
 import (
 	"context"
 	"net/http"
+
+	"github.com/mcosta74/hexkit/requests/idempotency"
 )
 
+// IdempotencyKeyHeader is the HTTP header carrying the idempotency key.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
 // RequestFunc may take information from an HTTP request and put it into
 // the request context. In Servers, RequestFuncs are executed before to invoke the request handler.
 type RequestFunc func(context.Context, *http.Request) context.Context
@@ -13,3 +18,12 @@ type RequestFunc func(context.Context, *http.Request) context.Context
 // to manipulate the ResponseWriter. ServerResponseFuncs are executed
 // after invoking the request handler but before to write the response.
 type ServerResponseFunc func(context.Context, http.ResponseWriter) context.Context
+
+// IdempotencyKeyToContext is a RequestFunc that stores the value of the
+// Idempotency-Key header in the context, for use by the idempotency middleware.
+func IdempotencyKeyToContext(ctx context.Context, r *http.Request) context.Context {
+	if key := r.Header.Get(IdempotencyKeyHeader); key != "" {
+		return idempotency.ContextWithKey(ctx, key)
+	}
+	return ctx
+}
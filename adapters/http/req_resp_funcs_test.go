@@ -0,0 +1,21 @@
+package http_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	kithttp "github.com/mcosta74/hexkit/adapters/http"
+	"github.com/mcosta74/hexkit/requests/idempotency"
+)
+
+func TestIdempotencyKeyToContext(t *testing.T) {
+	r := httptest.NewRequest("POST", "/", nil)
+	r.Header.Set(kithttp.IdempotencyKeyHeader, "abc")
+
+	ctx := kithttp.IdempotencyKeyToContext(context.Background(), r)
+
+	if key, _ := idempotency.KeyFromContext(ctx); key != "abc" {
+		t.Errorf("unexpected key: want=%q, got=%q", "abc", key)
+	}
+}
@@ -0,0 +1,36 @@
+package http
+
+import "reflect"
+
+// Operation documents a server.
+type Operation struct {
+	// ID uniquely identifies the operation.
+	ID string
+	// Summary is a short summary of what the operation does.
+	Summary string
+	// Description is a verbose explanation of the operation behavior.
+	Description string
+	// Tags are used to group the operations.
+	Tags []string
+	// Errors maps the HTTP status codes of the errors the operation may return
+	// to their description.
+	Errors map[int]string
+	// Deprecated declares the operation as deprecated.
+	Deprecated bool
+	// Hidden excludes the operation from the generated documentation.
+	Hidden bool
+}
+
+// Route describes a server registered on a [Group].
+type Route struct {
+	// Method is the HTTP method of the route.
+	Method string
+	// Path is the full path pattern of the route, prefix included.
+	Path string
+	// Request is the type of the request handled by the server.
+	Request reflect.Type
+	// Response is the type of the response returned by the server.
+	Response reflect.Type
+	// Operation is the documentation of the server.
+	Operation Operation
+}
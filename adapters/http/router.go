@@ -0,0 +1,69 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/mcosta74/hexkit/internal/jsonutil"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+// RawRequestDecoder is a DecodeRequestFunc which returns the HTTP request itself,
+// to expose a [requests.Router] through a [Server].
+func RawRequestDecoder(_ context.Context, r *http.Request) (*http.Request, error) {
+	return r, nil
+}
+
+// MethodKey is a [requests.Discriminator] which selects the operation by the HTTP method.
+func MethodKey(_ context.Context, r *http.Request) (string, error) {
+	return r.Method, nil
+}
+
+// HeaderKey returns a [requests.Discriminator] which selects the operation by the value of the header name.
+func HeaderKey(name string) requests.Discriminator[*http.Request] {
+	return func(_ context.Context, r *http.Request) (string, error) {
+		return r.Header.Get(name), nil
+	}
+}
+
+// DefaultJSONFieldKeyLimit is the maximum size of the body read by [JSONFieldKey].
+const DefaultJSONFieldKeyLimit = 1 << 20
+
+// JSONFieldKey returns a [requests.Discriminator] which selects the operation by the value
+// of the top-level string field of the JSON body. The body is restored so it can be decoded again.
+// Bodies larger than [DefaultJSONFieldKeyLimit] are rejected with http.StatusRequestEntityTooLarge.
+func JSONFieldKey(field string) requests.Discriminator[*http.Request] {
+	return JSONFieldKeyLimit(field, DefaultJSONFieldKeyLimit)
+}
+
+// JSONFieldKeyLimit is like [JSONFieldKey] but rejects the bodies larger than limit bytes.
+func JSONFieldKeyLimit(field string, limit int64) requests.Discriminator[*http.Request] {
+	return func(_ context.Context, r *http.Request) (string, error) {
+		if r.Body == nil {
+			return "", &statusError{code: http.StatusBadRequest, err: errors.New("missing request body")}
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, limit+1))
+		if err != nil {
+			return "", err
+		}
+		if int64(len(body)) > limit {
+			return "", &statusError{
+				code: http.StatusRequestEntityTooLarge,
+				err:  fmt.Errorf("request body larger than %d bytes", limit),
+			}
+		}
+		_ = r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		key, err := jsonutil.StringField(body, field)
+		if err != nil {
+			return "", &statusError{code: http.StatusBadRequest, err: err}
+		}
+		return key, nil
+	}
+}
@@ -0,0 +1,105 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	kithttp "github.com/mcosta74/hexkit/adapters/http"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+type greetRequest struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type sumRequest struct {
+	Type string `json:"type"`
+	A    int    `json:"a"`
+	B    int    `json:"b"`
+}
+
+func decodeJSON[Req any](_ context.Context, r *http.Request) (Req, error) {
+	var req Req
+	err := json.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+func TestRouter(t *testing.T) {
+	router := requests.NewRouter(kithttp.JSONFieldKey("type"))
+	requests.Route(router, "greet",
+		requests.HandlerFunc[greetRequest, string](func(_ context.Context, req greetRequest) (string, error) {
+			return "hello " + req.Name, nil
+		}),
+		decodeJSON[greetRequest],
+	)
+	requests.Route(router, "sum",
+		requests.HandlerFunc[sumRequest, int](func(_ context.Context, req sumRequest) (int, error) {
+			return req.A + req.B, nil
+		}),
+		decodeJSON[sumRequest],
+	)
+
+	server := httptest.NewServer(kithttp.NewServer(router, kithttp.RawRequestDecoder, kithttp.EncodeJSONResponse[any]))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{name: "Greet", body: `{"type":"greet","name":"world"}`, wantCode: http.StatusOK, wantBody: "\"hello world\"\n"},
+		{name: "Sum", body: `{"type":"sum","a":1,"b":2}`, wantCode: http.StatusOK, wantBody: "3\n"},
+		{name: "Unknown", body: `{"type":"mul"}`, wantCode: http.StatusBadRequest, wantBody: `unknown operation: "mul"`},
+		{name: "Invalid", body: `{`, wantCode: http.StatusBadRequest, wantBody: `decoding field "type": unexpected end of JSON input`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(server.URL, "application/json", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkResponse(t, resp, tt.wantCode, []byte(tt.wantBody))
+		})
+	}
+}
+
+func TestJSONFieldKeyLimit(t *testing.T) {
+	key := kithttp.JSONFieldKeyLimit("type", 16)
+
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"type":"greet"}`))
+	if got, err := key(context.Background(), r); err != nil || got != "greet" {
+		t.Errorf("unexpected key: want=greet, got=%q %v", got, err)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"type":"greet" }`))
+	_, err := key(context.Background(), r)
+	var sc kithttp.StatusCoder
+	if !errors.As(err, &sc) || sc.StatusCode() != http.StatusRequestEntityTooLarge {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMethodKey(t *testing.T) {
+	router := requests.NewRouter(kithttp.MethodKey)
+	requests.Route(router, http.MethodGet,
+		requests.HandlerFunc[struct{}, string](func(context.Context, struct{}) (string, error) { return "get", nil }),
+		kithttp.NoOpRequestDecoder[struct{}],
+	)
+
+	server := httptest.NewServer(kithttp.NewServer(router, kithttp.RawRequestDecoder, kithttp.EncodeJSONResponse[any]))
+	defer server.Close()
+
+	resp, _ := http.Get(server.URL)
+	checkResponse(t, resp, http.StatusOK, []byte("\"get\"\n"))
+
+	resp, _ = http.Post(server.URL, "text/plain", nil)
+	checkResponse(t, resp, http.StatusBadRequest, []byte(`unknown operation: "POST"`))
+}
@@ -3,6 +3,7 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 
@@ -19,6 +20,7 @@ type Server[Req, Resp any] struct {
 	after        []ServerResponseFunc
 	errorEncoder ErrorEncoder
 	errorHandler adapters.ErrorHandler
+	op           Operation
 }
 
 // NewServer creates a new server, which wraps the provided request handler and implements http.Handler.
@@ -82,6 +84,13 @@ func WithServerAfter[Req, Resp any](after ...ServerResponseFunc) ServerOption[Re
 	}
 }
 
+// WithOperation sets the documentation of the server, used when it's registered on a [Group].
+func WithOperation[Req, Resp any](op Operation) ServerOption[Req, Resp] {
+	return func(s *Server[Req, Resp]) {
+		s.op = op
+	}
+}
+
 // ServeHTTP implements http.Handler.
 func (s Server[Req, Resp]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -118,17 +127,24 @@ func (s Server[Req, Resp]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // ErrorEncoder encodes an error to the ResponseWriter.
 type ErrorEncoder func(ctx context.Context, err error, w http.ResponseWriter)
 
+// StatusCoder is checked by the default error encoders. If an error implements it,
+// the status code will be used when encoding the error. By default, the status
+// code is http.StatusInternalServerError.
+type StatusCoder interface {
+	StatusCode() int
+}
+
 // DefaultErrorEncoder is used when no error encoder is provided.
 func DefaultErrorEncoder(ctx context.Context, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "text/plan")
-	w.WriteHeader(http.StatusInternalServerError)
+	w.WriteHeader(ErrorStatusCode(err))
 	_, _ = w.Write([]byte(err.Error()))
 }
 
 // JSONErrorEncoder encodes errors in JSON format.
 func JSONErrorEncoder(ctx context.Context, err error, w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusInternalServerError)
+	w.WriteHeader(ErrorStatusCode(err))
 
 	data := struct {
 		Err string `json:"err,omitempty"`
@@ -140,6 +156,30 @@ func JSONErrorEncoder(ctx context.Context, err error, w http.ResponseWriter) {
 	_, _ = w.Write(body)
 }
 
+// ErrorStatusCode returns the HTTP status code for err: the code of the first error
+// in the chain implementing [StatusCoder], http.StatusBadRequest for
+// [requests.ErrUnknownOperation], http.StatusInternalServerError otherwise.
+func ErrorStatusCode(err error) int {
+	var sc StatusCoder
+	switch {
+	case errors.As(err, &sc):
+		return sc.StatusCode()
+	case errors.Is(err, requests.ErrUnknownOperation):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// statusError wraps an error with the HTTP status code to report it with.
+type statusError struct {
+	code int
+	err  error
+}
+
+func (e *statusError) Error() string   { return e.err.Error() }
+func (e *statusError) Unwrap() error   { return e.err }
+func (e *statusError) StatusCode() int { return e.code }
+
 // NoOpRequestDecoder it's a decoder that does nothing
 func NoOpRequestDecoder[Req any](context.Context, *http.Request) (Req, error) {
 	var req Req
@@ -0,0 +1,281 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mcosta74/hexkit/adapters"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+// LastEventIDHeader is the HTTP header carrying the ID of the last event received
+// by a reconnecting event stream client.
+const LastEventIDHeader = "Last-Event-ID"
+
+// ErrInvalidEvent is reported when the ID or the Type of an [Event] contains a line break,
+// which would let it inject fields or events into the stream.
+var ErrInvalidEvent = errors.New("invalid event")
+
+// Event is a server-sent event.
+type Event struct {
+	// ID is the event ID. When empty, the server assigns a sequence number.
+	ID string
+	// Type is the event type; clients treat events without type as "message".
+	Type string
+	// Data is the payload of the event. Each of its lines, ended by CR, LF or CRLF,
+	// is sent in a data field.
+	Data []byte
+}
+
+// EncodeEventFunc encodes a response of a stream into an event.
+type EncodeEventFunc[Resp any] func(ctx context.Context, resp Resp) (Event, error)
+
+// EncodeJSONEvent is an EncodeEventFunc that serializes the response as the JSON data of an event.
+func EncodeJSONEvent[Resp any](_ context.Context, resp Resp) (Event, error) {
+	b, err := json.Marshal(resp)
+	return Event{Data: b}, err
+}
+
+// SSEServer wraps a server streaming handler and implements a http.Handler writing the responses
+// as a text/event-stream.
+//
+// Errors occurring before the stream starts are encoded by the error encoder; errors
+// produced by the stream are sent as an "error" event ending the stream. The stream
+// is cancelled when the client disconnects.
+type SSEServer[Req, Resp any] struct {
+	h            requests.ServerStreamHandler[Req, Resp]
+	dec          DecodeRequestFunc[Req]
+	enc          EncodeEventFunc[Resp]
+	before       []RequestFunc
+	errorEncoder ErrorEncoder
+	errorHandler adapters.ErrorHandler
+	retry        time.Duration
+	heartbeat    time.Duration
+}
+
+// NewSSEServer creates a new server, which wraps the provided stream handler and implements http.Handler.
+func NewSSEServer[Req, Resp any](
+	h requests.ServerStreamHandler[Req, Resp],
+	dec DecodeRequestFunc[Req],
+	enc EncodeEventFunc[Resp],
+	options ...SSEServerOption[Req, Resp],
+) *SSEServer[Req, Resp] {
+	s := &SSEServer[Req, Resp]{
+		h:            h,
+		dec:          dec,
+		enc:          enc,
+		errorEncoder: DefaultErrorEncoder,
+		errorHandler: adapters.NewNoOpErrorHandler(),
+	}
+
+	for _, o := range options {
+		o(s)
+	}
+	return s
+}
+
+// SSEServerOption sets optional parameter for the SSE server.
+type SSEServerOption[Req, Resp any] func(s *SSEServer[Req, Resp])
+
+// WithSSEErrorEncoder sets the error encoder for the errors occurring before the stream starts.
+func WithSSEErrorEncoder[Req, Resp any](ee ErrorEncoder) SSEServerOption[Req, Resp] {
+	return func(s *SSEServer[Req, Resp]) {
+		s.errorEncoder = ee
+	}
+}
+
+// WithSSEErrorHandler sets the error handler for the server.
+func WithSSEErrorHandler[Req, Resp any](eh adapters.ErrorHandler) SSEServerOption[Req, Resp] {
+	return func(s *SSEServer[Req, Resp]) {
+		s.errorHandler = eh
+	}
+}
+
+// WithSSEErrorLogger sets a error handler for the server that logs errors.
+func WithSSEErrorLogger[Req, Resp any](logger *slog.Logger) SSEServerOption[Req, Resp] {
+	return func(s *SSEServer[Req, Resp]) {
+		s.errorHandler = adapters.NewSlogErrorHandler(logger)
+	}
+}
+
+// WithSSEServerBefore functions are executed on the HTTP request object
+// before the stream handler is invoked.
+func WithSSEServerBefore[Req, Resp any](before ...RequestFunc) SSEServerOption[Req, Resp] {
+	return func(s *SSEServer[Req, Resp]) {
+		s.before = append(s.before, before...)
+	}
+}
+
+// WithSSERetry sets the reconnection time sent to the clients.
+func WithSSERetry[Req, Resp any](d time.Duration) SSEServerOption[Req, Resp] {
+	return func(s *SSEServer[Req, Resp]) {
+		s.retry = d
+	}
+}
+
+// WithSSEHeartbeat sets the interval of the comments sent to keep idle connections alive.
+func WithSSEHeartbeat[Req, Resp any](d time.Duration) SSEServerOption[Req, Resp] {
+	return func(s *SSEServer[Req, Resp]) {
+		s.heartbeat = d
+	}
+}
+
+type lastEventIDKey struct{}
+
+// LastEventIDFromContext returns the ID of the last event received by a reconnecting client,
+// to let the stream handler resume from it.
+func LastEventIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(lastEventIDKey{}).(string)
+	return id, ok
+}
+
+// ServeHTTP implements http.Handler.
+func (s SSEServer[Req, Resp]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	// automatic IDs continue the sequence of the resumed stream
+	var seq uint64
+	if id := r.Header.Get(LastEventIDHeader); id != "" {
+		ctx = context.WithValue(ctx, lastEventIDKey{}, id)
+		seq, _ = strconv.ParseUint(id, 10, 64)
+	}
+
+	for _, f := range s.before {
+		ctx = f(ctx, r)
+	}
+
+	request, err := s.dec(ctx, r)
+	if err != nil {
+		s.errorHandler.Handle(ctx, err)
+		s.errorEncoder(ctx, err, w)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if s.retry > 0 {
+		_, _ = fmt.Fprintf(w, "retry: %d\n\n", s.retry.Milliseconds())
+	}
+	if err := rc.Flush(); err != nil {
+		s.errorHandler.Handle(ctx, err)
+		return
+	}
+
+	type item struct {
+		resp Resp
+		err  error
+	}
+	items := make(chan item)
+	go func() {
+		defer close(items)
+		for resp, err := range s.h.HandleStream(ctx, request) {
+			select {
+			case items <- item{resp, err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var heartbeat <-chan time.Time
+	if s.heartbeat > 0 {
+		t := time.NewTicker(s.heartbeat)
+		defer t.Stop()
+		heartbeat = t.C
+	}
+
+	for {
+		var ev Event
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-heartbeat:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			_ = rc.Flush()
+			continue
+
+		case it, ok := <-items:
+			if !ok {
+				return
+			}
+
+			err := it.err
+			if err == nil {
+				ev, err = s.enc(ctx, it.resp)
+			}
+			if err == nil && (strings.ContainsAny(ev.ID, "\r\n") || strings.ContainsAny(ev.Type, "\r\n")) {
+				err = fmt.Errorf("%w: line break in the ID or type", ErrInvalidEvent)
+			}
+			if err != nil {
+				s.errorHandler.Handle(ctx, err)
+				_ = writeEvent(w, Event{Type: "error", Data: []byte(err.Error())})
+				_ = rc.Flush()
+				return
+			}
+		}
+
+		if ev.ID == "" {
+			seq++
+			ev.ID = strconv.FormatUint(seq, 10)
+		}
+		if err := writeEvent(w, ev); err != nil {
+			s.errorHandler.Handle(ctx, err)
+			return
+		}
+		_ = rc.Flush()
+	}
+}
+
+func writeEvent(w io.Writer, ev Event) error {
+	var b bytes.Buffer
+	if ev.ID != "" {
+		b.WriteString("id: " + ev.ID + "\n")
+	}
+	if ev.Type != "" {
+		b.WriteString("event: " + ev.Type + "\n")
+	}
+	data := ev.Data
+	for {
+		i := bytes.IndexAny(data, "\r\n")
+		if i < 0 {
+			break
+		}
+		b.WriteString("data: ")
+		b.Write(data[:i])
+		b.WriteByte('\n')
+
+		if data[i] == '\r' && i+1 < len(data) && data[i+1] == '\n' {
+			i++
+		}
+		data = data[i+1:]
+	}
+	b.WriteString("data: ")
+	b.Write(data)
+	b.WriteByte('\n')
+	b.WriteByte('\n')
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
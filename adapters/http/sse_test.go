@@ -0,0 +1,163 @@
+package http_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	kithttp "github.com/mcosta74/hexkit/adapters/http"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+// countStream yields the numbers from the one following the Last-Event-ID up to n.
+func countStream(n int, failAt int) requests.ServerStreamHandlerFunc[struct{}, int] {
+	return func(ctx context.Context, _ struct{}) iter.Seq2[int, error] {
+		return func(yield func(int, error) bool) {
+			start := 1
+			if id, ok := kithttp.LastEventIDFromContext(ctx); ok {
+				last, _ := strconv.Atoi(id)
+				start = last + 1
+			}
+			for i := start; i <= n; i++ {
+				if i == failAt {
+					yield(0, errors.New("boom"))
+					return
+				}
+				if !yield(i, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func TestSSEServer(t *testing.T) {
+	get := func(t *testing.T, h http.Handler, lastEventID string) *http.Response {
+		t.Helper()
+
+		server := httptest.NewServer(h)
+		t.Cleanup(server.Close)
+
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		if lastEventID != "" {
+			req.Header.Set(kithttp.LastEventIDHeader, lastEventID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	t.Run("Stream", func(t *testing.T) {
+		resp := get(t, kithttp.NewSSEServer(
+			countStream(2, 0),
+			kithttp.NoOpRequestDecoder[struct{}],
+			kithttp.EncodeJSONEvent[int],
+			kithttp.WithSSERetry[struct{}, int](3*time.Second),
+		), "")
+
+		if want, got := "text/event-stream", resp.Header.Get("Content-Type"); want != got {
+			t.Errorf("unexpected content type: want=%q, got=%q", want, got)
+		}
+
+		body, _ := io.ReadAll(resp.Body)
+		if want, got := "retry: 3000\n\nid: 1\ndata: 1\n\nid: 2\ndata: 2\n\n", string(body); want != got {
+			t.Errorf("unexpected body:\nwant=%q\ngot= %q", want, got)
+		}
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		resp := get(t, kithttp.NewSSEServer(countStream(3, 0), kithttp.NoOpRequestDecoder[struct{}], kithttp.EncodeJSONEvent[int]), "1")
+
+		body, _ := io.ReadAll(resp.Body)
+		if want, got := "id: 2\ndata: 2\n\nid: 3\ndata: 3\n\n", string(body); want != got {
+			t.Errorf("unexpected body:\nwant=%q\ngot= %q", want, got)
+		}
+	})
+
+	t.Run("Stream Error", func(t *testing.T) {
+		resp := get(t, kithttp.NewSSEServer(
+			countStream(3, 2),
+			kithttp.NoOpRequestDecoder[struct{}],
+			func(_ context.Context, n int) (kithttp.Event, error) {
+				return kithttp.Event{Type: "count", Data: []byte("line\n" + strconv.Itoa(n))}, nil
+			},
+		), "")
+
+		body, _ := io.ReadAll(resp.Body)
+		if want, got := "id: 1\nevent: count\ndata: line\ndata: 1\n\nevent: error\ndata: boom\n\n", string(body); want != got {
+			t.Errorf("unexpected body:\nwant=%q\ngot= %q", want, got)
+		}
+	})
+
+	t.Run("Line Breaks", func(t *testing.T) {
+		resp := get(t, kithttp.NewSSEServer(
+			countStream(2, 0),
+			kithttp.NoOpRequestDecoder[struct{}],
+			func(_ context.Context, n int) (kithttp.Event, error) {
+				if n == 2 {
+					return kithttp.Event{Type: "count\nevent: forged"}, nil
+				}
+				return kithttp.Event{Data: []byte("a\rb\r\nc\n")}, nil
+			},
+		), "")
+
+		body, _ := io.ReadAll(resp.Body)
+		if want, got := "id: 1\ndata: a\ndata: b\ndata: c\ndata: \n\nevent: error\ndata: invalid event: line break in the ID or type\n\n", string(body); want != got {
+			t.Errorf("unexpected body:\nwant=%q\ngot= %q", want, got)
+		}
+	})
+
+	t.Run("Decode Error", func(t *testing.T) {
+		resp := get(t, kithttp.NewSSEServer(
+			countStream(1, 0),
+			func(context.Context, *http.Request) (struct{}, error) { return struct{}{}, errors.New("fail") },
+			kithttp.EncodeJSONEvent[int],
+		), "")
+
+		checkResponse(t, resp, http.StatusInternalServerError, []byte("fail"))
+	})
+
+	t.Run("Heartbeat And Disconnect", func(t *testing.T) {
+		ch := make(chan int)
+		done := make(chan struct{})
+		h := kithttp.NewSSEServer(
+			requests.ServerStreamHandlerFunc[struct{}, int](func(ctx context.Context, _ struct{}) iter.Seq2[int, error] {
+				go func() {
+					<-ctx.Done()
+					close(done)
+				}()
+				return requests.ChannelStream(ctx, ch)
+			}),
+			kithttp.NoOpRequestDecoder[struct{}],
+			kithttp.EncodeJSONEvent[int],
+			kithttp.WithSSEHeartbeat[struct{}, int](10*time.Millisecond),
+		)
+
+		resp := get(t, h, "")
+		line, err := bufio.NewReader(resp.Body).ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := ": heartbeat", strings.TrimSpace(line); want != got {
+			t.Errorf("unexpected line: want=%q, got=%q", want, got)
+		}
+
+		resp.Body.Close()
+		select {
+		case <-done:
+		case <-time.After(3 * time.Second):
+			t.Fatal("stream not cancelled on disconnect")
+		}
+	})
+}
@@ -0,0 +1,9 @@
+// Package asyncapi generates AsyncAPI 3 documents describing the NATS subscribers
+// and the micro endpoints of an application.
+//
+// Each endpoint is described by a channel addressed by its subject, on which the
+// application receives the requests, and a reply channel carrying its responses and
+// errors. Payload schemas are derived by reflection from the request and response types:
+// the `json` tags name the properties, the `validate` tags add constraints and the `doc`
+// tags add descriptions.
+package asyncapi
@@ -0,0 +1,89 @@
+package asyncapi
+
+import "github.com/mcosta74/hexkit/internal/jsonschema"
+
+// Version is the version of the AsyncAPI specification of the generated documents.
+const Version = "3.0.0"
+
+// Schema is a JSON Schema object.
+type Schema = jsonschema.Schema
+
+// Document is an AsyncAPI document.
+type Document struct {
+	AsyncAPI           string                `json:"asyncapi"`
+	Info               Info                  `json:"info"`
+	DefaultContentType string                `json:"defaultContentType,omitempty"`
+	Channels           map[string]*Channel   `json:"channels"`
+	Operations         map[string]*Operation `json:"operations"`
+	Components         *Components           `json:"components,omitempty"`
+}
+
+// Info provides metadata about the API.
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Channel describes a subject messages are exchanged on.
+type Channel struct {
+	// Address is the subject of the channel; nil when it's only known at runtime,
+	// as for the reply subjects.
+	Address     *string             `json:"address"`
+	Description string              `json:"description,omitempty"`
+	Messages    map[string]*Message `json:"messages,omitempty"`
+}
+
+// Message describes a message sent on a channel.
+type Message struct {
+	Name        string  `json:"name,omitempty"`
+	Summary     string  `json:"summary,omitempty"`
+	Description string  `json:"description,omitempty"`
+	ContentType string  `json:"contentType,omitempty"`
+	Headers     *Schema `json:"headers,omitempty"`
+	Payload     *Schema `json:"payload,omitempty"`
+}
+
+// Reference is a reference to another object of the document.
+type Reference struct {
+	Ref string `json:"$ref"`
+}
+
+// Operation describes an action performed by the application on a channel.
+type Operation struct {
+	Action      string             `json:"action"`
+	Channel     Reference          `json:"channel"`
+	Summary     string             `json:"summary,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Tags        []Tag              `json:"tags,omitempty"`
+	Messages    []Reference        `json:"messages,omitempty"`
+	Reply       *OperationReply    `json:"reply,omitempty"`
+	Bindings    *OperationBindings `json:"bindings,omitempty"`
+}
+
+// Tag groups operations.
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// OperationReply describes the reply of a request/reply operation.
+type OperationReply struct {
+	Channel  *Reference  `json:"channel,omitempty"`
+	Messages []Reference `json:"messages,omitempty"`
+}
+
+// OperationBindings holds the protocol specific information of an operation.
+type OperationBindings struct {
+	NATS *NATSOperationBinding `json:"nats,omitempty"`
+}
+
+// NATSOperationBinding holds the NATS specific information of an operation.
+type NATSOperationBinding struct {
+	Queue          string `json:"queue,omitempty"`
+	BindingVersion string `json:"bindingVersion"`
+}
+
+// Components holds the reusable objects of the document.
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
@@ -0,0 +1,238 @@
+package asyncapi
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+
+	kitnats "github.com/mcosta74/hexkit/adapters/nats"
+	kitmicro "github.com/mcosta74/hexkit/adapters/nats/micro"
+	"github.com/mcosta74/hexkit/internal/jsonschema"
+	"github.com/mcosta74/hexkit/requests"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+)
+
+// Endpoint describes a request handler subscribed to a subject.
+type Endpoint struct {
+	// Subject is the subject of the subscription.
+	Subject string
+	// Queue is the queue group of the subscription, if any.
+	Queue string
+	// Request is the type of the request handled by the endpoint.
+	Request reflect.Type
+	// Response is the type of the response returned by the endpoint.
+	Response reflect.Type
+	// ID uniquely identifies the endpoint; the subject is used when empty.
+	ID string
+	// Summary is a short summary of what the endpoint does.
+	Summary string
+	// Description is a verbose explanation of the endpoint behavior.
+	Description string
+	// Tags are used to group the endpoints.
+	Tags []string
+	// Micro reports whether the endpoint is a micro endpoint, replying the errors
+	// with the micro error headers instead of an error payload.
+	Micro bool
+	// Errors maps the micro error codes the endpoint may return to their description.
+	Errors map[string]string
+}
+
+// SubscriberEndpoint returns the endpoint described by the route of a subscriber.
+func SubscriberEndpoint(r kitnats.Route) Endpoint {
+	return Endpoint{
+		Subject:     r.Subject,
+		Queue:       r.Queue,
+		Request:     r.Request,
+		Response:    r.Response,
+		ID:          r.Operation.ID,
+		Summary:     r.Operation.Summary,
+		Description: r.Operation.Description,
+		Tags:        r.Operation.Tags,
+	}
+}
+
+// MicroEndpoint returns the endpoint described by the route of a micro handler.
+func MicroEndpoint(r kitmicro.Route) Endpoint {
+	return Endpoint{
+		Subject:     r.Subject,
+		Queue:       r.Queue,
+		Request:     r.Request,
+		Response:    r.Response,
+		ID:          r.Operation.ID,
+		Summary:     r.Operation.Summary,
+		Description: r.Operation.Description,
+		Tags:        r.Operation.Tags,
+		Micro:       true,
+		Errors:      r.Operation.Errors,
+	}
+}
+
+// Option sets optional parameters for the document generation.
+type Option func(c *config)
+
+type config struct {
+	errorType reflect.Type
+}
+
+// WithErrorType sets the type of the error payload replied by the subscribers.
+// The default is the payload published by the DefaultErrorEncoder of the NATS adapter.
+func WithErrorType[T any]() Option {
+	return func(c *config) {
+		c.errorType = reflect.TypeFor[T]()
+	}
+}
+
+// ErrorBody is the payload of the errors published by the DefaultErrorEncoder of the NATS adapter.
+type ErrorBody struct {
+	Err string `json:"err,omitempty" doc:"error message"`
+}
+
+const (
+	contentType        = "application/json"
+	natsBindingVersion = "0.1.0"
+)
+
+// Generate builds the document describing endpoints.
+func Generate(info Info, endpoints []Endpoint, options ...Option) *Document {
+	cfg := config{
+		errorType: reflect.TypeFor[ErrorBody](),
+	}
+	for _, o := range options {
+		o(&cfg)
+	}
+
+	gen := jsonschema.NewGenerator("#/components/schemas/")
+	doc := &Document{
+		AsyncAPI:           Version,
+		Info:               info,
+		DefaultContentType: contentType,
+		Channels:           make(map[string]*Channel),
+		Operations:         make(map[string]*Operation),
+	}
+
+	for _, e := range endpoints {
+		id := uniqueID(doc, e)
+
+		channel := &Channel{
+			Address:  &e.Subject,
+			Messages: map[string]*Message{"request": message("request", gen, e.Request)},
+		}
+		reply := &Channel{
+			Description: "Reply subject of the requests on " + e.Subject + ".",
+			Messages: map[string]*Message{
+				"response": message("response", gen, e.Response),
+				"error":    errorMessage(gen, e, cfg.errorType),
+			},
+		}
+		doc.Channels[id] = channel
+		doc.Channels[id+"Reply"] = reply
+
+		op := &Operation{
+			Action:      "receive",
+			Channel:     Reference{Ref: "#/channels/" + id},
+			Summary:     e.Summary,
+			Description: e.Description,
+			Messages:    []Reference{{Ref: "#/channels/" + id + "/messages/request"}},
+			Reply: &OperationReply{
+				Channel: &Reference{Ref: "#/channels/" + id + "Reply"},
+				Messages: []Reference{
+					{Ref: "#/channels/" + id + "Reply/messages/response"},
+					{Ref: "#/channels/" + id + "Reply/messages/error"},
+				},
+			},
+		}
+		for _, tag := range e.Tags {
+			op.Tags = append(op.Tags, Tag{Name: tag})
+		}
+		if e.Queue != "" {
+			op.Bindings = &OperationBindings{
+				NATS: &NATSOperationBinding{Queue: e.Queue, BindingVersion: natsBindingVersion},
+			}
+		}
+		doc.Operations[id] = op
+	}
+
+	if defs := gen.Definitions(); len(defs) > 0 {
+		doc.Components = &Components{Schemas: defs}
+	}
+	return doc
+}
+
+// Serve subscribes to subject, replying to each request with the document describing endpoints.
+func Serve(nc *nats.Conn, subject string, info Info, endpoints []Endpoint, options ...Option) (*nats.Subscription, error) {
+	doc := Generate(info, endpoints, options...)
+
+	s := kitnats.NewSubscriber(
+		requests.HandlerFunc[struct{}, *Document](func(context.Context, struct{}) (*Document, error) {
+			return doc, nil
+		}),
+		kitnats.NoOpRequestDecoder[struct{}],
+		kitnats.EncodeJSONResponse[*Document],
+	)
+	return nc.Subscribe(subject, s.ServeMsg(nc))
+}
+
+var invalidIDChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// uniqueID returns the identifier of the channels and the operation of e: neither id
+// nor id+"Reply" may be taken by the channels of another endpoint.
+func uniqueID(doc *Document, e Endpoint) string {
+	base := e.ID
+	if base == "" {
+		base = invalidIDChars.ReplaceAllString(e.Subject, "_")
+	}
+
+	taken := func(id string) bool {
+		return doc.Operations[id] != nil || doc.Channels[id] != nil || doc.Channels[id+"Reply"] != nil
+	}
+	id := base
+	for i := 2; taken(id); i++ {
+		id = base + strconv.Itoa(i)
+	}
+	return id
+}
+
+func message(name string, gen *jsonschema.Generator, t reflect.Type) *Message {
+	m := &Message{Name: name, ContentType: contentType}
+
+	schema := gen.Schema(t)
+	if resolved := gen.Resolve(schema); resolved.Type != "object" || len(resolved.Properties) > 0 || resolved.AdditionalProperties != nil {
+		m.Payload = schema
+	}
+	return m
+}
+
+func errorMessage(gen *jsonschema.Generator, e Endpoint, errorType reflect.Type) *Message {
+	if !e.Micro {
+		m := message("error", gen, errorType)
+		m.Summary = "Error replied by the endpoint."
+		return m
+	}
+
+	code := &Schema{Type: "string", Description: "error code"}
+	codes := make([]string, 0, len(e.Errors))
+	for c := range e.Errors {
+		codes = append(codes, c)
+	}
+	slices.Sort(codes)
+	for _, c := range codes {
+		code.Enum = append(code.Enum, c)
+		code.Description += "\n- " + c + ": " + e.Errors[c]
+	}
+
+	return &Message{
+		Name:    "error",
+		Summary: "Error replied by the endpoint, described by the micro error headers.",
+		Headers: &Schema{
+			Type: "object",
+			Properties: map[string]*Schema{
+				micro.ErrorHeader:     {Type: "string", Description: "error description"},
+				micro.ErrorCodeHeader: code,
+			},
+			Required: []string{micro.ErrorHeader, micro.ErrorCodeHeader},
+		},
+	}
+}
@@ -0,0 +1,169 @@
+package asyncapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	kitnats "github.com/mcosta74/hexkit/adapters/nats"
+	"github.com/mcosta74/hexkit/adapters/nats/asyncapi"
+	kitmicro "github.com/mcosta74/hexkit/adapters/nats/micro"
+	kittesting "github.com/mcosta74/hexkit/internal/testing"
+	"github.com/mcosta74/hexkit/requests"
+	"github.com/nats-io/nats.go/micro"
+)
+
+type getOrder struct {
+	ID string `json:"id" validate:"required"`
+}
+
+type Order struct {
+	ID   string `json:"id"`
+	Item string `json:"item"`
+}
+
+func endpoints() []asyncapi.Endpoint {
+	sub := kitnats.NewSubscriber(
+		requests.HandlerFunc[getOrder, Order](func(_ context.Context, req getOrder) (Order, error) { return Order{ID: req.ID}, nil }),
+		kitnats.NoOpRequestDecoder[getOrder],
+		kitnats.EncodeJSONResponse[Order],
+		kitnats.WithOperation[getOrder, Order](kitnats.Operation{Summary: "Get an order", Tags: []string{"orders"}}),
+	)
+	handler := kitmicro.NewHandler(
+		requests.HandlerFunc[struct{}, []Order](func(context.Context, struct{}) ([]Order, error) { return nil, nil }),
+		kitmicro.NoOpRequestDecoder[struct{}],
+		kitmicro.EncodeJSONResponse[[]Order],
+		kitmicro.WithOperation[struct{}, []Order](kitmicro.Operation{
+			ID:     "listOrders",
+			Errors: map[string]string{"503": "Store unavailable"},
+		}),
+	)
+
+	return []asyncapi.Endpoint{
+		asyncapi.SubscriberEndpoint(sub.Route("orders.get", "orders")),
+		asyncapi.MicroEndpoint(handler.Route("orders.list", "")),
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	doc := asyncapi.Generate(asyncapi.Info{Title: "Orders", Version: "1.0"}, endpoints())
+
+	if want, got := asyncapi.Version, doc.AsyncAPI; want != got {
+		t.Errorf("unexpected version: want=%q, got=%q", want, got)
+	}
+
+	t.Run("Subscriber", func(t *testing.T) {
+		ch := doc.Channels["orders_get"]
+		if ch == nil {
+			t.Fatalf("missing channel, channels: %v", doc.Channels)
+		}
+		if want, got := "orders.get", *ch.Address; want != got {
+			t.Errorf("unexpected address: want=%q, got=%q", want, got)
+		}
+		if want, got := "#/components/schemas/getOrder", ch.Messages["request"].Payload.Ref; want != got {
+			t.Errorf("unexpected request payload: want=%q, got=%q", want, got)
+		}
+
+		op := doc.Operations["orders_get"]
+		if want, got := "Get an order", op.Summary; want != got {
+			t.Errorf("unexpected summary: want=%q, got=%q", want, got)
+		}
+		if want, got := "orders", op.Bindings.NATS.Queue; want != got {
+			t.Errorf("unexpected queue: want=%q, got=%q", want, got)
+		}
+		if want, got := "#/channels/orders_getReply", op.Reply.Channel.Ref; want != got {
+			t.Errorf("unexpected reply channel: want=%q, got=%q", want, got)
+		}
+
+		reply := doc.Channels["orders_getReply"]
+		if reply.Address != nil {
+			t.Errorf("unexpected reply address %q", *reply.Address)
+		}
+		if want, got := "#/components/schemas/ErrorBody", reply.Messages["error"].Payload.Ref; want != got {
+			t.Errorf("unexpected error payload: want=%q, got=%q", want, got)
+		}
+	})
+
+	t.Run("Micro", func(t *testing.T) {
+		op := doc.Operations["listOrders"]
+		if op == nil {
+			t.Fatalf("missing operation, operations: %v", doc.Operations)
+		}
+		if want, got := micro.DefaultQueueGroup, op.Bindings.NATS.Queue; want != got {
+			t.Errorf("unexpected queue: want=%q, got=%q", want, got)
+		}
+
+		ch := doc.Channels["listOrders"]
+		if ch.Messages["request"].Payload != nil {
+			t.Error("unexpected payload for empty request")
+		}
+
+		reply := doc.Channels["listOrdersReply"]
+		b, _ := json.Marshal(reply.Messages["response"].Payload)
+		if want := `{"type":"array","items":{"$ref":"#/components/schemas/Order"}}`; want != string(b) {
+			t.Errorf("unexpected response payload:\nwant=%s\ngot= %s", want, b)
+		}
+
+		headers := reply.Messages["error"].Headers
+		if headers == nil {
+			t.Fatal("missing error headers")
+		}
+		if want, got := []any{"503"}, headers.Properties[micro.ErrorCodeHeader].Enum; len(got) != 1 || got[0] != want[0] {
+			t.Errorf("unexpected error codes: want=%v, got=%v", want, got)
+		}
+	})
+}
+
+func TestGenerateUniqueIDs(t *testing.T) {
+	endpoint := func(id string) asyncapi.Endpoint {
+		return asyncapi.Endpoint{Subject: "orders." + id, ID: id, Request: reflect.TypeFor[struct{}](), Response: reflect.TypeFor[struct{}]()}
+	}
+	doc := asyncapi.Generate(asyncapi.Info{Title: "Orders", Version: "1.0"}, []asyncapi.Endpoint{
+		endpoint("order"), endpoint("orderReply"), endpoint("itemReply"), endpoint("item"),
+	})
+
+	for id, subject := range map[string]string{
+		"order": "orders.order", "orderReply2": "orders.orderReply",
+		"itemReply": "orders.itemReply", "item2": "orders.item",
+	} {
+		if ch := doc.Channels[id]; ch == nil || *ch.Address != subject {
+			t.Errorf("unexpected channel %s: %+v", id, ch)
+		}
+		if doc.Operations[id] == nil || doc.Channels[id+"Reply"] == nil {
+			t.Errorf("missing operation or reply channel %s", id)
+		}
+	}
+	if want, got := 8, len(doc.Channels); want != got {
+		t.Errorf("unexpected channels: want=%d, got=%d", want, got)
+	}
+}
+
+func TestServe(t *testing.T) {
+	s, c := kittesting.NewNATSServerAndConn(t)
+	defer func() {
+		s.Shutdown()
+		s.WaitForShutdown()
+	}()
+	defer c.Close()
+
+	sub, err := asyncapi.Serve(c, "orders.asyncapi", asyncapi.Info{Title: "Orders", Version: "1.0"}, endpoints())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	msg, err := c.Request("orders.asyncapi", nil, 3*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc asyncapi.Document
+	if err := json.Unmarshal(msg.Data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := 2, len(doc.Operations); want != got {
+		t.Errorf("unexpected number of operations: want=%d, got=%d", want, got)
+	}
+}
@@ -0,0 +1,66 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mcosta74/hexkit/requests/auth"
+	"github.com/nats-io/nats.go"
+)
+
+// RequestInfoHeader carries the client information added by the NATS server to the
+// requests crossing a service import between accounts.
+const RequestInfoHeader = "Nats-Request-Info"
+
+// TokenToContext returns a RequestFunc authenticating the token found in header with
+// authn, e.g. the Authenticate method of an auth.JWTVerifier. A "Bearer " prefix is
+// removed from the token. The principal, or the failure, is stored in the context for
+// the auth middleware.
+func TokenToContext(header string, authn auth.TokenAuthenticator) RequestFunc {
+	return func(ctx context.Context, msg *nats.Msg) context.Context {
+		token := msg.Header.Get(header)
+		if scheme, t, ok := strings.Cut(token, " "); ok && strings.EqualFold(scheme, "Bearer") {
+			token = strings.TrimSpace(t)
+		}
+		if token == "" {
+			return ctx
+		}
+		p, err := authn(ctx, token)
+		return auth.ContextWithAuthentication(ctx, auth.MethodJWT, p, err)
+	}
+}
+
+// RequestInfoToContext returns a RequestFunc storing in the context the NATS user which
+// sent the request, as reported by the [RequestInfoHeader]: the principal has the user as
+// subject, and the client information as claims (e.g. "acc" for the account).
+//
+// The server sets the header only on the requests crossing a service import, and reports
+// the user only when the import shares the client details ("share: true"). Otherwise the
+// header can be forged by the clients of the account: serviceImportOnly states that the
+// subject is only reachable through a service import, so that the principal has
+// auth.MethodNATS. Without it, the principal has auth.MethodNATSUntrusted.
+func RequestInfoToContext(serviceImportOnly bool) RequestFunc {
+	method := auth.MethodNATSUntrusted
+	if serviceImportOnly {
+		method = auth.MethodNATS
+	}
+
+	return func(ctx context.Context, msg *nats.Msg) context.Context {
+		info := msg.Header.Get(RequestInfoHeader)
+		if info == "" {
+			return ctx
+		}
+
+		var claims map[string]any
+		if err := json.Unmarshal([]byte(info), &claims); err != nil {
+			return auth.ContextWithError(ctx, fmt.Errorf("%w: %s: %v", auth.ErrInvalidCredentials, RequestInfoHeader, err))
+		}
+		user, _ := claims["user"].(string)
+		if user == "" {
+			return auth.ContextWithError(ctx, fmt.Errorf("%w: %s: no user", auth.ErrInvalidCredentials, RequestInfoHeader))
+		}
+		return auth.ContextWithPrincipal(ctx, &auth.Principal{Subject: user, Method: method, Claims: claims})
+	}
+}
@@ -0,0 +1,168 @@
+package nats_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+
+	natsadapter "github.com/mcosta74/hexkit/adapters/nats"
+	kittesting "github.com/mcosta74/hexkit/internal/testing"
+	"github.com/mcosta74/hexkit/requests"
+	"github.com/mcosta74/hexkit/requests/auth"
+)
+
+var whoami = auth.New[struct{}, string]()(requests.HandlerFunc[struct{}, string](func(ctx context.Context, _ struct{}) (string, error) {
+	p, _ := auth.PrincipalFromContext(ctx)
+	return p.Method + ":" + p.Subject, nil
+}))
+
+func TestTokenToContext(t *testing.T) {
+	_, c := kittesting.NewNATSServerAndConn(t)
+
+	h := natsadapter.NewSubscriber(whoami,
+		natsadapter.NoOpRequestDecoder[struct{}],
+		natsadapter.EncodeJSONResponse[string],
+		natsadapter.WithSubscriberBefore[struct{}, string](
+			natsadapter.TokenToContext("Authorization", auth.StaticAPIKeys(map[string]string{"k1": "svc"})),
+		),
+		natsadapter.WithErrorEncoder[struct{}, string](natsadapter.ServiceErrorEncoder),
+	)
+	sub, err := c.Subscribe("natsadapter.whoami", h.ServeMsg(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	call := func(token string) (string, error) {
+		p := natsadapter.NewPublisher(c, "natsadapter.whoami",
+			natsadapter.EncodeJSONRequest[struct{}],
+			natsadapter.DecodeErrorResponse(natsadapter.DecodeJSONResponse[string]),
+			natsadapter.WithPublisherBefore[struct{}, string](func(ctx context.Context, msg *nats.Msg) context.Context {
+				if token != "" {
+					msg.Header.Set("Authorization", token)
+				}
+				return ctx
+			}),
+		)
+		return p.Handle(context.Background(), struct{}{})
+	}
+
+	if resp, err := call("Bearer k1"); err != nil || resp != "apikey:svc" {
+		t.Errorf("unexpected result: resp=%q, err=%v", resp, err)
+	}
+
+	for _, token := range []string{"", "k2"} {
+		_, err := call(token)
+		var se *natsadapter.ServiceError
+		if !errors.As(err, &se) || se.Code != "401" {
+			t.Errorf("unexpected error for token %q: %v", token, err)
+		}
+	}
+}
+
+func TestRequestInfoToContext(t *testing.T) {
+	conf := filepath.Join(t.TempDir(), "server.conf")
+	err := os.WriteFile(conf, []byte(`
+		accounts {
+			SVC {
+				users = [{user: svc, password: svc}]
+				exports = [{service: "natsadapter.whoami"}]
+			}
+			APP {
+				users = [{user: alice, password: alice}]
+				imports = [{service: {account: SVC, subject: "natsadapter.whoami"}, share: true}]
+			}
+		}
+	`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts, err := server.ProcessConfigFile(conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Host, opts.Port = "localhost", server.RANDOM_PORT
+	s, err := server.NewServer(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.Start()
+	defer s.Shutdown()
+	if !s.ReadyForConnections(5 * time.Second) {
+		t.Fatal("NATS server not ready in time")
+	}
+
+	connect := func(user string) *nats.Conn {
+		nc, err := nats.Connect(s.ClientURL(), nats.UserInfo(user, user))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(nc.Close)
+		return nc
+	}
+	svc, app := connect("svc"), connect("alice")
+
+	h := natsadapter.NewSubscriber(whoami,
+		natsadapter.NoOpRequestDecoder[struct{}],
+		natsadapter.EncodeJSONResponse[string],
+		natsadapter.WithSubscriberBefore[struct{}, string](natsadapter.RequestInfoToContext(true)),
+		natsadapter.WithErrorEncoder[struct{}, string](natsadapter.ServiceErrorEncoder),
+	)
+	sub, err := svc.Subscribe("natsadapter.whoami", h.ServeMsg(svc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	_ = svc.Flush()
+
+	call := func(nc *nats.Conn) (string, error) {
+		p := natsadapter.NewPublisher(nc, "natsadapter.whoami",
+			natsadapter.EncodeJSONRequest[struct{}],
+			natsadapter.DecodeErrorResponse(natsadapter.DecodeJSONResponse[string]),
+		)
+		return p.Handle(context.Background(), struct{}{})
+	}
+
+	if resp, err := call(app); err != nil || resp != "nats:alice" {
+		t.Errorf("unexpected result: resp=%q, err=%v", resp, err)
+	}
+
+	// requests from the same account don't cross a service import
+	_, err = call(svc)
+	var se *natsadapter.ServiceError
+	if !errors.As(err, &se) || se.Code != "401" {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	t.Run("Untrusted", func(t *testing.T) {
+		h := natsadapter.NewSubscriber(whoami,
+			natsadapter.NoOpRequestDecoder[struct{}],
+			natsadapter.EncodeJSONResponse[string],
+			natsadapter.WithSubscriberBefore[struct{}, string](natsadapter.RequestInfoToContext(false)),
+		)
+		sub, err := svc.Subscribe("natsadapter.forged", h.ServeMsg(svc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sub.Unsubscribe()
+
+		p := natsadapter.NewPublisher(svc, "natsadapter.forged",
+			natsadapter.EncodeJSONRequest[struct{}],
+			natsadapter.DecodeJSONResponse[string],
+			natsadapter.WithPublisherBefore[struct{}, string](func(ctx context.Context, msg *nats.Msg) context.Context {
+				msg.Header.Set(natsadapter.RequestInfoHeader, `{"user":"admin"}`)
+				return ctx
+			}),
+		)
+		if resp, err := p.Handle(context.Background(), struct{}{}); err != nil || resp != "nats-untrusted:admin" {
+			t.Errorf("unexpected result: resp=%q, err=%v", resp, err)
+		}
+	})
+}
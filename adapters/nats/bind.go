@@ -0,0 +1,180 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/mcosta74/hexkit/internal/bind"
+	"github.com/nats-io/nats.go"
+)
+
+// Binding sources, as used in the struct tags understood by [BindRequest].
+const (
+	BindSubject = "subject"
+	BindHeader  = "header"
+	BindData    = "data"
+)
+
+var bindSources = []string{BindSubject, BindHeader}
+
+// FieldError reports a failure binding a single field of the request.
+type FieldError struct {
+	// Source is where the value comes from, e.g. "subject" or "header".
+	Source string
+	// Name is the name of the parameter in the source.
+	Name string
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("%s: %v", e.Source, e.Err)
+	}
+	return fmt.Sprintf("%s parameter %q: %v", e.Source, e.Name, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// BindingError aggregates the errors found binding a request.
+type BindingError struct {
+	Errors []*FieldError
+}
+
+func (e *BindingError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Error()
+	}
+	return "binding request: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the field errors.
+func (e *BindingError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, fe := range e.Errors {
+		errs[i] = fe
+	}
+	return errs
+}
+
+// ErrorCode reports binding errors as bad requests to the micro adapter.
+func (e *BindingError) ErrorCode() string {
+	return "400"
+}
+
+// ErrMissingValue is reported for required parameters which are not found in the message.
+var ErrMissingValue = errors.New("missing value")
+
+// BindRequest is a DecodeRequestFunc which populates a struct request from the NATS message.
+//
+// The payload, if any, is decoded as JSON first. Then the fields tagged with `subject`
+// (the named tokens of a [SubjectPattern], see [SubjectTokensFromContext]) and `header`
+// are populated, taking precedence over the payload. A tag may be followed by ",required".
+//
+//	type GetOrder struct {
+//		Tenant  string `subject:"tenant"`
+//		ID      int    `subject:"id,required"`
+//		TraceID string `header:"X-Trace-Id"`
+//	}
+//
+// Field types are the ones supported by the HTTP adapter. All the failures are
+// reported together in a [*BindingError].
+func BindRequest[Req any](ctx context.Context, msg *nats.Msg) (Req, error) {
+	var req Req
+
+	v := reflect.ValueOf(&req).Elem()
+	if v.Kind() != reflect.Struct {
+		return req, fmt.Errorf("binding request: %s is not a struct", v.Type())
+	}
+
+	var errs []*FieldError
+	if len(msg.Data) > 0 {
+		if err := json.Unmarshal(msg.Data, &req); err != nil {
+			errs = append(errs, &FieldError{Source: BindData, Err: err})
+		}
+	}
+
+	tokens := SubjectTokensFromContext(ctx)
+	for _, f := range bindFieldsOf(v.Type()) {
+		var values []string
+		switch f.source {
+		case BindSubject:
+			if token, ok := tokens[f.name]; ok {
+				values = []string{token}
+			}
+		case BindHeader:
+			values = msg.Header.Values(f.name)
+		}
+
+		if len(values) == 0 {
+			if f.required {
+				errs = append(errs, &FieldError{Source: f.source, Name: f.name, Err: ErrMissingValue})
+			}
+			continue
+		}
+
+		if err := bind.SetField(v.FieldByIndex(f.index), values); err != nil {
+			errs = append(errs, &FieldError{Source: f.source, Name: f.name, Err: err})
+		}
+	}
+
+	if len(errs) > 0 {
+		return req, &BindingError{Errors: errs}
+	}
+	return req, nil
+}
+
+type bindField struct {
+	index    []int
+	source   string
+	name     string
+	required bool
+}
+
+var bindFieldsCache sync.Map
+
+// bindFieldsOf returns the tagged fields of the struct type t.
+func bindFieldsOf(t reflect.Type) []bindField {
+	if fields, ok := bindFieldsCache.Load(t); ok {
+		return fields.([]bindField)
+	}
+
+	var fields []bindField
+	for _, sf := range reflect.VisibleFields(t) {
+		if !sf.IsExported() || (sf.Anonymous && sf.Type.Kind() == reflect.Struct) {
+			continue
+		}
+		if bind.HasPointerParent(t, sf.Index) {
+			continue
+		}
+
+		for _, source := range bindSources {
+			tag, ok := sf.Tag.Lookup(source)
+			if !ok || tag == "-" {
+				continue
+			}
+
+			name, opts, _ := strings.Cut(tag, ",")
+			if name == "" {
+				name = sf.Name
+			}
+			fields = append(fields, bindField{
+				index:    sf.Index,
+				source:   source,
+				name:     name,
+				required: opts == "required",
+			})
+		}
+	}
+
+	bindFieldsCache.Store(t, fields)
+	return fields
+}
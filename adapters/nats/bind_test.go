@@ -0,0 +1,75 @@
+package nats_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	natsadapter "github.com/mcosta74/hexkit/adapters/nats"
+	kittesting "github.com/mcosta74/hexkit/internal/testing"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+type getOrder struct {
+	Tenant  string   `subject:"tenant"`
+	ID      int      `subject:"id,required"`
+	TraceID string   `header:"X-Trace-Id"`
+	Fields  []string `json:"fields"`
+}
+
+func TestBindRequest(t *testing.T) {
+	s, c := kittesting.NewNATSServerAndConn(t)
+	defer func() {
+		s.Shutdown()
+		s.WaitForShutdown()
+	}()
+	defer c.Close()
+
+	p := natsadapter.MustParseSubject("orders.{tenant}.{id}.get")
+	sub := natsadapter.NewSubscriber(
+		requests.HandlerFunc[getOrder, getOrder](func(_ context.Context, req getOrder) (getOrder, error) {
+			return req, nil
+		}),
+		natsadapter.BindRequest[getOrder],
+		natsadapter.EncodeJSONResponse[getOrder],
+		natsadapter.WithSubjectPattern[getOrder, getOrder](p),
+	)
+	ns, err := c.Subscribe(p.Subject(), sub.ServeMsg(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ns.Unsubscribe()
+
+	msg := nats.NewMsg("orders.acme.42.get")
+	msg.Header.Set("X-Trace-Id", "abc")
+	msg.Data = []byte(`{"fields":["total"]}`)
+	reply, err := c.RequestMsg(msg, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := natsadapter.DecodeJSONResponse[getOrder](context.Background(), reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Tenant != "acme" || got.ID != 42 || got.TraceID != "abc" || len(got.Fields) != 1 {
+		t.Errorf("unexpected request: %+v", got)
+	}
+
+	t.Run("Binding Error", func(t *testing.T) {
+		ctx := p.ContextWithTokens(context.Background(), "orders.acme.x.get")
+		_, err := natsadapter.BindRequest[getOrder](ctx, &nats.Msg{Subject: "orders.acme.x.get"})
+
+		var be *natsadapter.BindingError
+		if !errors.As(err, &be) || len(be.Errors) != 1 || be.Errors[0].Name != "id" {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		_, err = natsadapter.BindRequest[getOrder](context.Background(), &nats.Msg{})
+		if !errors.Is(err, natsadapter.ErrMissingValue) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
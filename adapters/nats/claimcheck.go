@@ -0,0 +1,152 @@
+package nats
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// ClaimCheckHeader carries the name of the object holding the payload of a message.
+const ClaimCheckHeader = "Nats-Claim-Check"
+
+// ClaimCheck moves the payloads too large for a NATS message to an Object Store bucket:
+// the message carries the name of the object in the [ClaimCheckHeader] instead.
+//
+// Objects aren't deleted when claimed, since a message may be received more than once:
+// configure a TTL on the bucket, or call Cleanup periodically.
+//
+// The receivers fetch whatever object the header names: any client allowed to publish
+// to their subjects can make them read any object of the bucket. Use a bucket dedicated
+// to the claim checks, and don't store anything else in it.
+type ClaimCheck struct {
+	os        jetstream.ObjectStore
+	threshold int
+}
+
+// NewClaimCheck creates a claim check storing in os the payloads bigger than threshold bytes,
+// e.g. the max payload of the connection minus some room for the headers.
+func NewClaimCheck(os jetstream.ObjectStore, threshold int) *ClaimCheck {
+	return &ClaimCheck{
+		os:        os,
+		threshold: threshold,
+	}
+}
+
+// Check stores data in the bucket when it's bigger than the threshold, setting the
+// claim check header. It returns the payload to send: nil when data was stored, data otherwise.
+func (c *ClaimCheck) Check(ctx context.Context, header nats.Header, data []byte) ([]byte, error) {
+	if len(data) <= c.threshold {
+		return data, nil
+	}
+
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	name := hex.EncodeToString(b[:])
+
+	if _, err := c.os.PutBytes(ctx, name, data); err != nil {
+		return nil, err
+	}
+	header.Set(ClaimCheckHeader, name)
+	return nil, nil
+}
+
+// Claim returns the payload stored in the bucket when header carries a claim check,
+// removing the claim check header, data otherwise.
+func (c *ClaimCheck) Claim(ctx context.Context, header nats.Header, data []byte) ([]byte, error) {
+	name := header.Get(ClaimCheckHeader)
+	if name == "" {
+		return data, nil
+	}
+	b, err := c.os.GetBytes(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	header.Del(ClaimCheckHeader)
+	return b, nil
+}
+
+// Cleanup deletes the objects stored more than maxAge ago and returns how many were deleted.
+func (c *ClaimCheck) Cleanup(ctx context.Context, maxAge time.Duration) (int, error) {
+	objects, err := c.os.List(ctx)
+	if errors.Is(err, jetstream.ErrNoObjectsFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	threshold := time.Now().Add(-maxAge)
+	for _, o := range objects {
+		if o.ModTime.After(threshold) {
+			continue
+		}
+		if err := c.os.Delete(ctx, o.Name); err != nil && !errors.Is(err, jetstream.ErrObjectNotFound) {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// EncodeClaimCheckRequest wraps enc so that oversized requests are stored with c.
+func EncodeClaimCheckRequest[Req any](c *ClaimCheck, enc EncodeRequestFunc[Req]) EncodeRequestFunc[Req] {
+	return func(ctx context.Context, msg *nats.Msg, req Req) error {
+		if err := enc(ctx, msg, req); err != nil {
+			return err
+		}
+		if msg.Header == nil {
+			msg.Header = nats.Header{}
+		}
+		data, err := c.Check(ctx, msg.Header, msg.Data)
+		msg.Data = data
+		return err
+	}
+}
+
+// DecodeClaimCheckRequest wraps dec so that requests stored with c are fetched before decoding.
+func DecodeClaimCheckRequest[Req any](c *ClaimCheck, dec DecodeRequestFunc[Req]) DecodeRequestFunc[Req] {
+	return func(ctx context.Context, msg *nats.Msg) (Req, error) {
+		data, err := c.Claim(ctx, msg.Header, msg.Data)
+		if err != nil {
+			var zero Req
+			return zero, err
+		}
+		msg.Data = data
+		return dec(ctx, msg)
+	}
+}
+
+// EncodeClaimCheckResponse returns an EncodeReplyFunc encoding the responses into the
+// reply with enc, e.g. [EncodeJSONChunk], and storing them with c when oversized.
+func EncodeClaimCheckResponse[Resp any](c *ClaimCheck, enc func(ctx context.Context, msg *nats.Msg, resp Resp) error) EncodeReplyFunc[Resp] {
+	return func(ctx context.Context, reply *Reply, resp Resp) error {
+		if err := enc(ctx, reply.Msg, resp); err != nil {
+			return err
+		}
+		data, err := c.Check(ctx, reply.Header, reply.Data)
+		if err != nil {
+			return err
+		}
+		reply.Data = data
+		return nil
+	}
+}
+
+// DecodeClaimCheckResponse wraps dec so that responses stored with c are fetched before decoding.
+func DecodeClaimCheckResponse[Resp any](c *ClaimCheck, dec DecodeResponseFunc[Resp]) DecodeResponseFunc[Resp] {
+	return func(ctx context.Context, msg *nats.Msg) (Resp, error) {
+		data, err := c.Claim(ctx, msg.Header, msg.Data)
+		if err != nil {
+			var zero Resp
+			return zero, err
+		}
+		msg.Data = data
+		return dec(ctx, msg)
+	}
+}
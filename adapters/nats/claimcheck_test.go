@@ -0,0 +1,101 @@
+package nats_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	natsadapter "github.com/mcosta74/hexkit/adapters/nats"
+	kittesting "github.com/mcosta74/hexkit/internal/testing"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+func newClaimCheck(t *testing.T, c *nats.Conn) (*natsadapter.ClaimCheck, jetstream.ObjectStore) {
+	t.Helper()
+
+	js, err := jetstream.New(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os, err := js.CreateObjectStore(context.Background(), jetstream.ObjectStoreConfig{Bucket: "claims"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return natsadapter.NewClaimCheck(os, 64), os
+}
+
+func TestClaimCheck(t *testing.T) {
+	s, c := kittesting.NewJetStreamServerAndConn(t)
+	defer func() {
+		s.Shutdown()
+		s.WaitForShutdown()
+	}()
+	defer c.Close()
+
+	cc, os := newClaimCheck(t, c)
+
+	var received nats.Header
+	sub := natsadapter.NewSubscriber(
+		requests.HandlerFunc[string, string](func(_ context.Context, req string) (string, error) {
+			return strings.ToUpper(req), nil
+		}),
+		natsadapter.DecodeClaimCheckRequest(cc, func(_ context.Context, msg *nats.Msg) (string, error) {
+			received = msg.Header
+			var req string
+			err := json.Unmarshal(msg.Data, &req)
+			return req, err
+		}),
+		natsadapter.EncodeClaimCheckResponse(cc, natsadapter.EncodeJSONChunk[string]),
+	)
+	ns, err := c.Subscribe("natsadapter.upper", sub.ServeMsg(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ns.Unsubscribe()
+
+	p := natsadapter.NewPublisher(c, "natsadapter.upper",
+		natsadapter.EncodeClaimCheckRequest(cc, natsadapter.EncodeJSONRequest[string]),
+		natsadapter.DecodeClaimCheckResponse(cc, natsadapter.DecodeJSONResponse[string]),
+	)
+
+	t.Run("Small Payload", func(t *testing.T) {
+		resp, err := p.Handle(context.Background(), "small")
+		if err != nil || resp != "SMALL" {
+			t.Errorf("unexpected response: %q, %v", resp, err)
+		}
+		if received.Get(natsadapter.ClaimCheckHeader) != "" {
+			t.Error("small payload stored")
+		}
+	})
+
+	t.Run("Large Payload", func(t *testing.T) {
+		large := strings.Repeat("a", 1000)
+		resp, err := p.Handle(context.Background(), large)
+		if err != nil || resp != strings.ToUpper(large) {
+			t.Errorf("unexpected response: %q, %v", resp, err)
+		}
+		if received.Get(natsadapter.ClaimCheckHeader) != "" {
+			t.Error("claim check header not removed")
+		}
+		if objects, _ := os.List(context.Background()); len(objects) != 2 {
+			t.Errorf("large payloads not stored: %d objects", len(objects))
+		}
+	})
+
+	t.Run("Cleanup", func(t *testing.T) {
+		if n, err := cc.Cleanup(context.Background(), time.Hour); n != 0 || err != nil {
+			t.Errorf("unexpected cleanup result: n=%d, err=%v", n, err)
+		}
+		if n, err := cc.Cleanup(context.Background(), 0); n != 2 || err != nil {
+			t.Errorf("unexpected cleanup result: n=%d, err=%v", n, err)
+		}
+		if _, err := os.List(context.Background()); err != jetstream.ErrNoObjectsFound {
+			t.Errorf("objects not deleted: %v", err)
+		}
+	})
+}
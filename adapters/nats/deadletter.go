@@ -0,0 +1,227 @@
+package nats
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Headers added to the messages forwarded to the dead-letter subject, besides the original ones.
+const (
+	// DeadLetterErrorHeader carries the error of the failed message.
+	DeadLetterErrorHeader = "Nats-Dead-Letter-Error"
+	// DeadLetterCauseHeader is repeated for each error wrapped by the error of the failed message.
+	DeadLetterCauseHeader = "Nats-Dead-Letter-Cause"
+	// DeadLetterSubjectHeader carries the subject of the failed message.
+	DeadLetterSubjectHeader = "Nats-Dead-Letter-Subject"
+	// DeadLetterTimeHeader carries the time of the last failure, in RFC 3339 format.
+	DeadLetterTimeHeader = "Nats-Dead-Letter-Time"
+	// DeadLetterDeliveriesHeader carries the number of deliveries of the failed message.
+	DeadLetterDeliveriesHeader = "Nats-Dead-Letter-Deliveries"
+	// DeadLetterStreamHeader carries the stream of the failed JetStream message.
+	DeadLetterStreamHeader = "Nats-Dead-Letter-Stream"
+	// DeadLetterSequenceHeader carries the stream sequence of the failed JetStream message.
+	DeadLetterSequenceHeader = "Nats-Dead-Letter-Sequence"
+	// DeadLetterPublishedHeader carries the time the failed JetStream message was stored, in RFC 3339 format.
+	DeadLetterPublishedHeader = "Nats-Dead-Letter-Published"
+	// DeadLetterOriginalHeaderPrefix prefixes the Nats-Msg-Id and Nats-Expected-* headers
+	// of the failed message, which would make JetStream deduplicate or reject the
+	// dead-letter message: Nats-Msg-Id is kept as Nats-Dead-Letter-Original-Msg-Id.
+	DeadLetterOriginalHeaderPrefix = "Nats-Dead-Letter-Original-"
+)
+
+// ErrNotDeadLetter is returned when replaying a message without the [DeadLetterSubjectHeader].
+var ErrNotDeadLetter = errors.New("nats: not a dead-letter message")
+
+// newDeadLetterMsg copies msg to subject, adding the error metadata.
+func newDeadLetterMsg(subject string, msg *nats.Msg, err error) *nats.Msg {
+	dl := nats.NewMsg(subject)
+	for k, v := range msg.Header {
+		if k == jetstream.MsgIDHeader || strings.HasPrefix(k, "Nats-Expected-") {
+			k = DeadLetterOriginalHeaderPrefix + strings.TrimPrefix(k, "Nats-")
+		}
+		dl.Header[k] = v
+	}
+	dl.Header.Set(DeadLetterErrorHeader, err.Error())
+	for _, cause := range causes(err) {
+		dl.Header.Add(DeadLetterCauseHeader, cause.Error())
+	}
+	dl.Header.Set(DeadLetterSubjectHeader, msg.Subject)
+	dl.Header.Set(DeadLetterTimeHeader, time.Now().UTC().Format(time.RFC3339Nano))
+	dl.Data = msg.Data
+	return dl
+}
+
+// causes returns the errors wrapped by err, depth first.
+func causes(err error) []error {
+	var errs []error
+	var walk func(error)
+	walk = func(err error) {
+		switch u := err.(type) {
+		case interface{ Unwrap() error }:
+			if cause := u.Unwrap(); cause != nil {
+				errs = append(errs, cause)
+				walk(cause)
+			}
+		case interface{ Unwrap() []error }:
+			for _, cause := range u.Unwrap() {
+				errs = append(errs, cause)
+				walk(cause)
+			}
+		}
+	}
+	walk(err)
+	return errs
+}
+
+// ReplayMsg returns a copy of the dead-letter message dl addressed to its origin subject,
+// without the dead-letter headers. The Nats-Msg-Id header is dropped too, otherwise
+// JetStream would discard the replayed message as a duplicate.
+func ReplayMsg(dl *nats.Msg) (*nats.Msg, error) {
+	subject := dl.Header.Get(DeadLetterSubjectHeader)
+	if subject == "" {
+		return nil, ErrNotDeadLetter
+	}
+
+	msg := nats.NewMsg(subject)
+	for k, v := range dl.Header {
+		if strings.HasPrefix(k, "Nats-Dead-Letter-") || k == jetstream.MsgIDHeader {
+			continue
+		}
+		msg.Header[k] = v
+	}
+	msg.Data = dl.Data
+	return msg, nil
+}
+
+// ReplayOption sets optional parameters for [Replay].
+type ReplayOption func(c *replayConfig)
+
+type replayConfig struct {
+	filter func(*nats.Msg) bool
+	keep   bool
+}
+
+// WithReplayFilter replays only the messages for which filter returns true.
+func WithReplayFilter(filter func(dl *nats.Msg) bool) ReplayOption {
+	return func(c *replayConfig) {
+		c.filter = filter
+	}
+}
+
+// WithReplayKeep keeps the replayed messages in the dead-letter stream.
+// By default they are deleted once re-published.
+func WithReplayKeep() ReplayOption {
+	return func(c *replayConfig) {
+		c.keep = true
+	}
+}
+
+// Replay re-publishes the messages of the dead-letter stream to their origin subject,
+// and returns how many were replayed. Messages coming from a JetStream stream are
+// published with JetStream and acknowledged, the others are published with core NATS.
+func Replay(ctx context.Context, nc *nats.Conn, stream string, options ...ReplayOption) (int, error) {
+	var cfg replayConfig
+	for _, o := range options {
+		o(&cfg)
+	}
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		return 0, err
+	}
+	s, err := js.Stream(ctx, stream)
+	if err != nil {
+		return 0, err
+	}
+	info, err := s.Info(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	for seq := info.State.FirstSeq; seq > 0 && seq <= info.State.LastSeq; seq++ {
+		raw, err := s.GetMsg(ctx, seq)
+		if errors.Is(err, jetstream.ErrMsgNotFound) {
+			continue
+		}
+		if err != nil {
+			return replayed, err
+		}
+
+		dl := &nats.Msg{Subject: raw.Subject, Header: raw.Header, Data: raw.Data}
+		if cfg.filter != nil && !cfg.filter(dl) {
+			continue
+		}
+		msg, err := ReplayMsg(dl)
+		if err != nil {
+			return replayed, err
+		}
+
+		if dl.Header.Get(DeadLetterStreamHeader) != "" {
+			_, err = js.PublishMsg(ctx, msg)
+		} else {
+			err = nc.PublishMsg(msg)
+		}
+		if err != nil {
+			return replayed, err
+		}
+
+		if !cfg.keep {
+			if err := s.DeleteMsg(ctx, seq); err != nil {
+				return replayed, err
+			}
+		}
+		replayed++
+	}
+	return replayed, nil
+}
+
+// failureCounter counts the failures of core NATS messages.
+type failureCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// maxTrackedFailures bounds the memory of a failureCounter; when reached, the counts restart.
+const maxTrackedFailures = 10000
+
+func newFailureCounter() *failureCounter {
+	return &failureCounter{
+		counts: make(map[string]int),
+	}
+}
+
+func (c *failureCounter) inc(key string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.counts[key]; !ok && len(c.counts) >= maxTrackedFailures {
+		clear(c.counts)
+	}
+	c.counts[key]++
+	return c.counts[key]
+}
+
+func (c *failureCounter) reset(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.counts, key)
+}
+
+// failureKey identifies msg across retries of the publisher.
+func failureKey(msg *nats.Msg) string {
+	if id := msg.Header.Get(jetstream.MsgIDHeader); id != "" {
+		return id
+	}
+	sum := sha256.Sum256(msg.Data)
+	return msg.Subject + " " + hex.EncodeToString(sum[:])
+}
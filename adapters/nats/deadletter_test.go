@@ -0,0 +1,199 @@
+package nats_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	natsadapter "github.com/mcosta74/hexkit/adapters/nats"
+	"github.com/mcosta74/hexkit/events"
+	kittesting "github.com/mcosta74/hexkit/internal/testing"
+)
+
+var errStock = errors.New("out of stock")
+
+func TestDeadLetterCoreNATS(t *testing.T) {
+	s, c := kittesting.NewNATSServerAndConn(t)
+	defer func() {
+		s.Shutdown()
+		s.WaitForShutdown()
+	}()
+	defer c.Close()
+
+	sub := natsadapter.NewEventSubscriber(
+		events.HandlerFunc[orderCreated](func(_ context.Context, e orderCreated) error {
+			return fmt.Errorf("reserving order %d: %w", e.ID, errStock)
+		}),
+		decodeOrderCreated,
+		natsadapter.WithDeadLetter[orderCreated]("orders.dlq"),
+		natsadapter.WithMaxDeliveries[orderCreated](2),
+	)
+	ns, err := c.Subscribe("orders.created", sub.ServeMsg(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ns.Unsubscribe()
+
+	dlq, err := c.SubscribeSync("orders.dlq")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := nats.NewMsg("orders.created")
+	msg.Header.Set(jetstream.MsgIDHeader, "order-1")
+	msg.Data = []byte(`{"id":1}`)
+
+	if err := c.PublishMsg(msg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dlq.NextMsg(100 * time.Millisecond); !errors.Is(err, nats.ErrTimeout) {
+		t.Fatalf("dead-lettered before max deliveries: %v", err)
+	}
+
+	if err := c.PublishMsg(msg); err != nil {
+		t.Fatal(err)
+	}
+	dl, err := dlq.NextMsg(time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "2", dl.Header.Get(natsadapter.DeadLetterDeliveriesHeader); want != got {
+		t.Errorf("unexpected deliveries: want=%s, got=%s", want, got)
+	}
+	if causes := dl.Header.Values(natsadapter.DeadLetterCauseHeader); len(causes) != 1 || causes[0] != errStock.Error() {
+		t.Errorf("unexpected causes: %v", causes)
+	}
+
+	t.Run("Poison Message", func(t *testing.T) {
+		if err := c.Publish("orders.created", []byte("not json")); err != nil {
+			t.Fatal(err)
+		}
+		dl, err := dlq.NextMsg(time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := "1", dl.Header.Get(natsadapter.DeadLetterDeliveriesHeader); want != got {
+			t.Errorf("unexpected deliveries: want=%s, got=%s", want, got)
+		}
+	})
+
+	t.Run("Replay Message", func(t *testing.T) {
+		msg, err := natsadapter.ReplayMsg(dl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg.Subject != "orders.created" || msg.Header.Get(jetstream.MsgIDHeader) != "" || msg.Header.Get(natsadapter.DeadLetterErrorHeader) != "" {
+			t.Errorf("unexpected replay message: %+v", msg)
+		}
+
+		if _, err := natsadapter.ReplayMsg(msg); !errors.Is(err, natsadapter.ErrNotDeadLetter) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestDeadLetterJetStream(t *testing.T) {
+	s, c := kittesting.NewJetStreamServerAndConn(t)
+	defer func() {
+		s.Shutdown()
+		s.WaitForShutdown()
+	}()
+	defer c.Close()
+
+	ctx := context.Background()
+	js, err := jetstream.New(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: "ORDERS", Subjects: []string{"orders.created"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dlqStream, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: "DLQ", Subjects: []string{"orders.dlq"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cons, err := stream.CreateConsumer(ctx, jetstream.ConsumerConfig{Durable: "orders", AckPolicy: jetstream.AckExplicitPolicy})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var healthy atomic.Bool
+	handled := make(chan int, 10)
+	sub := natsadapter.NewEventSubscriber(
+		events.HandlerFunc[orderCreated](func(_ context.Context, e orderCreated) error {
+			if !healthy.Load() {
+				return errStock
+			}
+			handled <- e.ID
+			return nil
+		}),
+		decodeOrderCreated,
+		natsadapter.WithDeadLetter[orderCreated]("orders.dlq"),
+		natsadapter.WithMaxDeliveries[orderCreated](3),
+	)
+	cc, err := cons.Consume(sub.ServeJetStream(js))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Stop()
+
+	if _, err := js.Publish(ctx, "orders.created", []byte(`{"id":7}`), jetstream.WithMsgID("order-7"), jetstream.WithExpectStream("ORDERS")); err != nil {
+		t.Fatal(err)
+	}
+
+	var dl *jetstream.RawStreamMsg
+	for i := 0; i < 40; i++ {
+		if dl, err = dlqStream.GetMsg(ctx, 1); err == nil {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "3", dl.Header.Get(natsadapter.DeadLetterDeliveriesHeader); want != got {
+		t.Errorf("unexpected deliveries: want=%s, got=%s", want, got)
+	}
+	if dl.Header.Get(natsadapter.DeadLetterPublishedHeader) == "" {
+		t.Error("missing published time")
+	}
+	if dl.Header.Get(jetstream.MsgIDHeader) != "" || dl.Header.Get("Nats-Expected-Stream") != "" {
+		t.Errorf("unexpected publish headers: %v", dl.Header)
+	}
+	if want, got := "order-7", dl.Header.Get(natsadapter.DeadLetterOriginalHeaderPrefix+"Msg-Id"); want != got {
+		t.Errorf("unexpected original message ID: want=%s, got=%s", want, got)
+	}
+	if want, got := "ORDERS", dl.Header.Get(natsadapter.DeadLetterOriginalHeaderPrefix+"Expected-Stream"); want != got {
+		t.Errorf("unexpected original expected stream: want=%s, got=%s", want, got)
+	}
+
+	healthy.Store(true)
+	n, err := natsadapter.Replay(ctx, c, "DLQ")
+	if err != nil || n != 1 {
+		t.Fatalf("unexpected replay result: n=%d, err=%v", n, err)
+	}
+
+	select {
+	case id := <-handled:
+		if id != 7 {
+			t.Errorf("unexpected event: %d", id)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("replayed event not handled")
+	}
+
+	info, err := dlqStream.Info(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.State.Msgs != 0 {
+		t.Errorf("replayed message not deleted: %d messages left", info.State.Msgs)
+	}
+}
@@ -1,2 +1,16 @@
-// Package http provides general purpose NATS binding for request handlers.
+// Package nats provides general purpose NATS binding for request handlers.
+//
+// # Replies
+//
+// A [Subscriber] builds the reply of each request as a [Reply] message: the after
+// functions and the encoders fill its headers and data, then the subscriber publishes
+// it. This is a breaking change from the earlier versions, where the encoders published
+// the reply themselves, and changes the signatures of:
+//
+//   - [NewSubscriber], which takes an [EncodeReplyFunc] instead of an [EncodeResponseFunc];
+//   - [WithErrorEncoder], which takes an [ErrorReplyEncoder] instead of an [ErrorEncoder];
+//   - [WithSubscriberAfter], which takes [ReplyFunc]s instead of [SubscriberResponseFunc]s.
+//
+// The existing functions can be adapted with [AdaptEncodeResponseFunc],
+// [AdaptErrorEncoder] and [AdaptSubscriberResponseFunc].
 package nats
@@ -2,6 +2,7 @@ package nats
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/nats-io/nats.go"
 )
@@ -9,5 +10,19 @@ import (
 // DecodeRequestFunc extracts user-domain request object from a publisher request object.
 type DecodeRequestFunc[Req any] func(ctx context.Context, msg *nats.Msg) (request Req, err error)
 
-// EncodeResponseFunc encodes the provided response object to the subscriber reply.
+// EncodeReplyFunc encodes the provided response object into the subscriber reply.
+type EncodeReplyFunc[Resp any] func(ctx context.Context, reply *Reply, resp Resp) error
+
+// EncodeResponseFunc encodes the provided response object and publishes it to the subject
+// of the subscriber reply. Use [AdaptEncodeResponseFunc] to use it with a [Subscriber].
 type EncodeResponseFunc[Resp any] func(ctx context.Context, subject string, nc *nats.Conn, resp Resp) error
+
+// EncodeJSONResponse is an EncodeReplyFunc that serializes the response as JSON.
+func EncodeJSONResponse[Resp any](_ context.Context, reply *Reply, resp Resp) error {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	reply.Data = b
+	return nil
+}
@@ -0,0 +1,127 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/mcosta74/hexkit/requests"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+)
+
+// Headers of the error replies, the same used by the nats.go micro services.
+const (
+	ServiceErrorHeader     = micro.ErrorHeader
+	ServiceErrorCodeHeader = micro.ErrorCodeHeader
+)
+
+// ErrorCoder is checked by the service error encoders. If an error implements it,
+// the code will be used when encoding the error. By default, the code is "500".
+// The micro and websocket adapters check the same interface.
+type ErrorCoder interface {
+	ErrorCode() string
+}
+
+// ErrorCode returns the service error code for err: the code of the first error
+// in the chain implementing [ErrorCoder], "400" for [requests.ErrUnknownOperation],
+// "500" otherwise.
+func ErrorCode(err error) string {
+	var ec ErrorCoder
+	switch {
+	case errors.As(err, &ec):
+		return ec.ErrorCode()
+	case errors.Is(err, requests.ErrUnknownOperation):
+		return "400"
+	}
+	return "500"
+}
+
+// codeError attaches an error code to err.
+type codeError struct {
+	code string
+	err  error
+}
+
+func (e *codeError) Error() string     { return e.err.Error() }
+func (e *codeError) Unwrap() error     { return e.err }
+func (e *codeError) ErrorCode() string { return e.code }
+
+// ServiceErrorEncoder is an ErrorReplyEncoder replying errors like the micro services do:
+// in the [ServiceErrorHeader] and [ServiceErrorCodeHeader] headers, with no data.
+func ServiceErrorEncoder(_ context.Context, err error, reply *Reply) {
+	setServiceErrorHeaders(reply, err)
+	reply.Data = nil
+}
+
+// WithServiceErrorHeaders returns an ErrorReplyEncoder adding the micro error headers
+// to the reply encoded by ee, e.g. to keep the JSON body of [DefaultErrorEncoder] for
+// the existing clients.
+func WithServiceErrorHeaders(ee ErrorReplyEncoder) ErrorReplyEncoder {
+	return func(ctx context.Context, err error, reply *Reply) {
+		ee(ctx, err, reply)
+		setServiceErrorHeaders(reply, err)
+	}
+}
+
+func setServiceErrorHeaders(reply *Reply, err error) {
+	if reply.Header == nil {
+		reply.Header = nats.Header{}
+	}
+	reply.Header.Set(ServiceErrorHeader, err.Error())
+	reply.Header.Set(ServiceErrorCodeHeader, ErrorCode(err))
+}
+
+// ServiceError is an error replied by a service, see [DecodeServiceError].
+type ServiceError struct {
+	Code        string
+	Description string
+}
+
+func (e *ServiceError) Error() string {
+	return e.Description
+}
+
+// ErrorCode implements ErrorCoder, so a gateway replies with the code of the service.
+func (e *ServiceError) ErrorCode() string {
+	return e.Code
+}
+
+// DecodeServiceError returns the error carried by a reply, or nil if the reply is not
+// an error. It recognises both the micro error headers and the JSON body of
+// [DefaultErrorEncoder], whose code is "500".
+//
+// The body of [DefaultErrorEncoder] is only recognised in replies without headers, but
+// it can't be told apart from a response encoded as a JSON object whose only member
+// is a non-empty "err" string: services with such responses should reply their errors
+// with [ServiceErrorEncoder] and be called without DecodeErrorResponse.
+func DecodeServiceError(msg *nats.Msg) error {
+	if desc, code := msg.Header.Get(ServiceErrorHeader), msg.Header.Get(ServiceErrorCodeHeader); desc != "" || code != "" {
+		return &ServiceError{Code: code, Description: desc}
+	}
+	if len(msg.Header) > 0 {
+		return nil
+	}
+
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(msg.Data, &body); err != nil || len(body) != 1 {
+		return nil
+	}
+	var desc string
+	if err := json.Unmarshal(body["err"], &desc); err != nil || desc == "" {
+		return nil
+	}
+	return &ServiceError{Code: "500", Description: desc}
+}
+
+// DecodeErrorResponse wraps dec, returning the error replied by the service
+// (see [DecodeServiceError]) instead of decoding it.
+func DecodeErrorResponse[Resp any](dec DecodeResponseFunc[Resp]) DecodeResponseFunc[Resp] {
+	return func(ctx context.Context, msg *nats.Msg) (Resp, error) {
+		if err := DecodeServiceError(msg); err != nil {
+			var zero Resp
+			return zero, err
+		}
+		return dec(ctx, msg)
+	}
+}
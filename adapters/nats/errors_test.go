@@ -0,0 +1,142 @@
+package nats_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+
+	natsadapter "github.com/mcosta74/hexkit/adapters/nats"
+	kittesting "github.com/mcosta74/hexkit/internal/testing"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+type codedError struct{}
+
+func (codedError) Error() string     { return "not found" }
+func (codedError) ErrorCode() string { return "404" }
+
+func TestServiceErrors(t *testing.T) {
+	_, c := kittesting.NewNATSServerAndConn(t)
+
+	failing := requests.HandlerFunc[string, string](func(context.Context, string) (string, error) {
+		return "", codedError{}
+	})
+
+	call := func(t *testing.T, subject string) (*nats.Msg, error) {
+		t.Helper()
+
+		var reply *nats.Msg
+		p := natsadapter.NewPublisher(c, subject,
+			natsadapter.EncodeJSONRequest[string],
+			natsadapter.DecodeErrorResponse(natsadapter.DecodeJSONResponse[string]),
+			natsadapter.WithPublisherAfter[string, string](func(ctx context.Context, msg *nats.Msg) context.Context {
+				reply = msg
+				return ctx
+			}),
+		)
+		_, err := p.Handle(context.Background(), "hello")
+		return reply, err
+	}
+
+	assertServiceError := func(t *testing.T, err error, code, desc string) {
+		t.Helper()
+
+		var se *natsadapter.ServiceError
+		if !errors.As(err, &se) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if se.Code != code || se.Description != desc {
+			t.Errorf("unexpected service error: want=%s %q, got=%s %q", code, desc, se.Code, se.Description)
+		}
+	}
+
+	t.Run("Headers", func(t *testing.T) {
+		h := natsadapter.NewSubscriber(failing,
+			natsadapter.NoOpRequestDecoder[string],
+			natsadapter.EncodeJSONResponse[string],
+			natsadapter.WithErrorEncoder[string, string](natsadapter.ServiceErrorEncoder),
+		)
+		sub, err := c.Subscribe("natsadapter.errors.headers", h.ServeMsg(c))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sub.Unsubscribe()
+
+		reply, err := call(t, "natsadapter.errors.headers")
+		assertServiceError(t, err, "404", "not found")
+		if len(reply.Data) != 0 {
+			t.Errorf("unexpected data: %s", reply.Data)
+		}
+	})
+
+	t.Run("Headers And Body", func(t *testing.T) {
+		h := natsadapter.NewSubscriber(failing,
+			natsadapter.NoOpRequestDecoder[string],
+			natsadapter.EncodeJSONResponse[string],
+			natsadapter.WithErrorEncoder[string, string](natsadapter.WithServiceErrorHeaders(natsadapter.DefaultErrorEncoder)),
+		)
+		sub, err := c.Subscribe("natsadapter.errors.both", h.ServeMsg(c))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sub.Unsubscribe()
+
+		reply, err := call(t, "natsadapter.errors.both")
+		assertServiceError(t, err, "404", "not found")
+		if want, got := `{"err":"not found"}`, string(reply.Data); want != got {
+			t.Errorf("unexpected data: want=%s, got=%s", want, got)
+		}
+	})
+
+	t.Run("Body", func(t *testing.T) {
+		h := natsadapter.NewSubscriber(failing,
+			natsadapter.NoOpRequestDecoder[string],
+			natsadapter.EncodeJSONResponse[string],
+		)
+		sub, err := c.Subscribe("natsadapter.errors.body", h.ServeMsg(c))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sub.Unsubscribe()
+
+		_, err = call(t, "natsadapter.errors.body")
+		assertServiceError(t, err, "500", "not found")
+	})
+
+	t.Run("Micro", func(t *testing.T) {
+		svc, err := micro.AddService(c, micro.Config{
+			Name:    "errors",
+			Version: "1.0.0",
+			Endpoint: &micro.EndpointConfig{
+				Subject: "natsadapter.errors.micro",
+				Handler: micro.HandlerFunc(func(req micro.Request) {
+					_ = req.Error("403", "forbidden", nil)
+				}),
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer svc.Stop()
+
+		_, err = call(t, "natsadapter.errors.micro")
+		assertServiceError(t, err, "403", "forbidden")
+	})
+
+	t.Run("Not An Error", func(t *testing.T) {
+		for _, data := range []string{`"hello"`, `{"err":""}`, `{"err":"x","data":"y"}`, `not json`} {
+			if err := natsadapter.DecodeServiceError(&nats.Msg{Data: []byte(data)}); err != nil {
+				t.Errorf("unexpected error for %s: %v", data, err)
+			}
+		}
+
+		// the body of DefaultErrorEncoder is only recognised without headers
+		msg := &nats.Msg{Header: nats.Header{"Content-Type": {"application/json"}}, Data: []byte(`{"err":"x"}`)}
+		if err := natsadapter.DecodeServiceError(msg); err != nil {
+			t.Errorf("unexpected error with headers: %v", err)
+		}
+	})
+}
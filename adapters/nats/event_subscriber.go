@@ -0,0 +1,218 @@
+package nats
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/mcosta74/hexkit/adapters"
+	"github.com/mcosta74/hexkit/events"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// EventSubscriber wraps an event handler and consumes one-way messages: no reply
+// is ever sent, and the messages failing too many times are forwarded to the
+// dead-letter subject, if any.
+//
+// With JetStream the messages are acknowledged only once handled or dead-lettered,
+// providing at-least-once processing: handlers should be idempotent.
+type EventSubscriber[E any] struct {
+	h             events.Handler[E]
+	dec           DecodeRequestFunc[E]
+	before        []RequestFunc
+	errorHandler  adapters.ErrorHandler
+	deadLetter    string
+	maxDeliveries int
+	failures      *failureCounter
+}
+
+// NewEventSubscriber creates a new event subscriber, which wraps the provided event handler.
+func NewEventSubscriber[E any](
+	h events.Handler[E],
+	dec DecodeRequestFunc[E],
+	options ...EventSubscriberOption[E],
+) *EventSubscriber[E] {
+	s := &EventSubscriber[E]{
+		h:            h,
+		dec:          dec,
+		errorHandler: adapters.NewNoOpErrorHandler(),
+		failures:     newFailureCounter(),
+	}
+
+	for _, o := range options {
+		o(s)
+	}
+	return s
+}
+
+// EventSubscriberOption sets optional parameter for the event subscriber.
+type EventSubscriberOption[E any] func(s *EventSubscriber[E])
+
+// WithEventErrorHandler sets the error handler for the event subscriber.
+func WithEventErrorHandler[E any](eh adapters.ErrorHandler) EventSubscriberOption[E] {
+	return func(s *EventSubscriber[E]) {
+		s.errorHandler = eh
+	}
+}
+
+// WithEventErrorLogger sets a error handler for the event subscriber that logs errors.
+func WithEventErrorLogger[E any](logger *slog.Logger) EventSubscriberOption[E] {
+	return func(s *EventSubscriber[E]) {
+		s.errorHandler = adapters.NewSlogErrorHandler(logger)
+	}
+}
+
+// WithEventSubscriberBefore functions are executed on the NATS message object
+// before the event handler is invoked.
+func WithEventSubscriberBefore[E any](before ...RequestFunc) EventSubscriberOption[E] {
+	return func(s *EventSubscriber[E]) {
+		s.before = append(s.before, before...)
+	}
+}
+
+// WithDeadLetter sets the subject where the exhausted messages are forwarded, with their
+// original headers and the failure metadata (see [DeadLetterErrorHeader] and following).
+// With JetStream the subject must be bound to a stream.
+func WithDeadLetter[E any](subject string) EventSubscriberOption[E] {
+	return func(s *EventSubscriber[E]) {
+		s.deadLetter = subject
+	}
+}
+
+// WithMaxDeliveries sets how many times a message may fail before it's exhausted: with
+// JetStream it's compared to the delivery count of the message, with core NATS to the
+// failures counted in-process by Nats-Msg-Id header, or by subject and payload.
+// Messages failing to decode are exhausted at once.
+//
+// By default messages are exhausted at the first failure when there's a dead-letter
+// subject, and retried forever otherwise.
+func WithMaxDeliveries[E any](n int) EventSubscriberOption[E] {
+	return func(s *EventSubscriber[E]) {
+		s.maxDeliveries = n
+	}
+}
+
+// ServeMsg provides nats.MsgHandler for core NATS subscriptions. Core NATS doesn't
+// redeliver messages: failing messages are dead-lettered once exhausted, and dropped
+// until then, expecting the publisher to retry them.
+func (s *EventSubscriber[E]) ServeMsg(nc *nats.Conn) nats.MsgHandler {
+	return func(msg *nats.Msg) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		key := ""
+		if s.maxDeliveries > 0 {
+			key = failureKey(msg)
+		}
+
+		poison, err := s.handle(ctx, msg)
+		if err == nil {
+			if key != "" {
+				s.failures.reset(key)
+			}
+			return
+		}
+		s.errorHandler.Handle(ctx, err)
+
+		deliveries := 1
+		if key != "" {
+			deliveries = s.failures.inc(key)
+		}
+		if !poison && !s.exhausted(deliveries) {
+			return
+		}
+		if key != "" {
+			s.failures.reset(key)
+		}
+
+		if s.deadLetter == "" {
+			return
+		}
+		dl := newDeadLetterMsg(s.deadLetter, msg, err)
+		dl.Header.Set(DeadLetterDeliveriesHeader, strconv.Itoa(deliveries))
+		if err := nc.PublishMsg(dl); err != nil {
+			s.errorHandler.Handle(ctx, err)
+		}
+	}
+}
+
+// ServeJetStream provides jetstream.MessageHandler for JetStream consumers. Handled
+// messages are acknowledged, failing messages are negatively acknowledged for redelivery
+// until exhausted. Exhausted messages are forwarded to the dead-letter subject with js
+// and terminated; they are negatively acknowledged when forwarding fails.
+func (s *EventSubscriber[E]) ServeJetStream(js jetstream.JetStream) jetstream.MessageHandler {
+	return func(jm jetstream.Msg) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		msg := &nats.Msg{
+			Subject: jm.Subject(),
+			Reply:   jm.Reply(),
+			Header:  nats.Header(jm.Headers()),
+			Data:    jm.Data(),
+		}
+
+		poison, err := s.handle(ctx, msg)
+		if err == nil {
+			s.ack(ctx, jm.Ack())
+			return
+		}
+		s.errorHandler.Handle(ctx, err)
+
+		md, merr := jm.Metadata()
+		if merr != nil {
+			s.errorHandler.Handle(ctx, merr)
+			s.ack(ctx, jm.Nak())
+			return
+		}
+		if !poison && !s.exhausted(int(md.NumDelivered)) {
+			s.ack(ctx, jm.Nak())
+			return
+		}
+		if s.deadLetter == "" {
+			s.ack(ctx, jm.Term())
+			return
+		}
+
+		dl := newDeadLetterMsg(s.deadLetter, msg, err)
+		dl.Header.Set(DeadLetterStreamHeader, md.Stream)
+		dl.Header.Set(DeadLetterSequenceHeader, strconv.FormatUint(md.Sequence.Stream, 10))
+		dl.Header.Set(DeadLetterDeliveriesHeader, strconv.FormatUint(md.NumDelivered, 10))
+		dl.Header.Set(DeadLetterPublishedHeader, md.Timestamp.UTC().Format(time.RFC3339Nano))
+		if _, err := js.PublishMsg(ctx, dl); err != nil {
+			s.errorHandler.Handle(ctx, err)
+			s.ack(ctx, jm.Nak())
+			return
+		}
+		s.ack(ctx, jm.Term())
+	}
+}
+
+// exhausted reports whether a message failing after the given deliveries must not be retried.
+func (s *EventSubscriber[E]) exhausted(deliveries int) bool {
+	if s.maxDeliveries <= 0 {
+		return s.deadLetter != ""
+	}
+	return deliveries >= s.maxDeliveries
+}
+
+// handle decodes and handles msg; poison reports whether msg can't be decoded, so retrying it is useless.
+func (s *EventSubscriber[E]) handle(ctx context.Context, msg *nats.Msg) (poison bool, err error) {
+	for _, f := range s.before {
+		ctx = f(ctx, msg)
+	}
+
+	event, err := s.dec(ctx, msg)
+	if err != nil {
+		return true, err
+	}
+	return false, s.h.HandleEvent(ctx, event)
+}
+
+func (s *EventSubscriber[E]) ack(ctx context.Context, err error) {
+	if err != nil {
+		s.errorHandler.Handle(ctx, err)
+	}
+}
@@ -0,0 +1,160 @@
+package nats_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	natsadapter "github.com/mcosta74/hexkit/adapters/nats"
+	"github.com/mcosta74/hexkit/events"
+	kittesting "github.com/mcosta74/hexkit/internal/testing"
+)
+
+type orderCreated struct {
+	ID int `json:"id"`
+}
+
+func decodeOrderCreated(_ context.Context, msg *nats.Msg) (orderCreated, error) {
+	var e orderCreated
+	err := json.Unmarshal(msg.Data, &e)
+	return e, err
+}
+
+func rejectOdd(handled chan<- int) events.Handler[orderCreated] {
+	return events.HandlerFunc[orderCreated](func(_ context.Context, e orderCreated) error {
+		if e.ID%2 == 1 {
+			return errors.New("odd order")
+		}
+		handled <- e.ID
+		return nil
+	})
+}
+
+func TestEventSubscriber(t *testing.T) {
+	s, c := kittesting.NewNATSServerAndConn(t)
+	defer func() {
+		s.Shutdown()
+		s.WaitForShutdown()
+	}()
+	defer c.Close()
+
+	handled := make(chan int, 10)
+	sub := natsadapter.NewEventSubscriber(rejectOdd(handled), decodeOrderCreated,
+		natsadapter.WithDeadLetter[orderCreated]("orders.dlq"),
+	)
+
+	ns, err := c.Subscribe("orders.created", sub.ServeMsg(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ns.Unsubscribe()
+
+	dlq, err := c.SubscribeSync("orders.dlq")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := nats.NewMsg("orders.created")
+	msg.Header.Set("Trace-Id", "abc")
+	for _, data := range []string{`{"id":2}`, `{"id":1}`} {
+		msg.Data = []byte(data)
+		if err := c.PublishMsg(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case id := <-handled:
+		if id != 2 {
+			t.Errorf("unexpected event: %d", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event not handled")
+	}
+
+	dl, err := dlq.NextMsg(time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(dl.Data) != `{"id":1}` ||
+		dl.Header.Get("Trace-Id") != "abc" ||
+		dl.Header.Get(natsadapter.DeadLetterErrorHeader) != "odd order" ||
+		dl.Header.Get(natsadapter.DeadLetterSubjectHeader) != "orders.created" {
+		t.Errorf("unexpected dead letter: %+v", dl)
+	}
+}
+
+func TestEventSubscriberJetStream(t *testing.T) {
+	s, c := kittesting.NewJetStreamServerAndConn(t)
+	defer func() {
+		s.Shutdown()
+		s.WaitForShutdown()
+	}()
+	defer c.Close()
+
+	ctx := context.Background()
+	js, err := jetstream.New(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: "ORDERS", Subjects: []string{"orders.created"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dlqStream, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: "DLQ", Subjects: []string{"orders.dlq"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cons, err := stream.CreateConsumer(ctx, jetstream.ConsumerConfig{Durable: "orders", AckPolicy: jetstream.AckExplicitPolicy})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handled := make(chan int, 10)
+	sub := natsadapter.NewEventSubscriber(rejectOdd(handled), decodeOrderCreated,
+		natsadapter.WithDeadLetter[orderCreated]("orders.dlq"),
+	)
+	cc, err := cons.Consume(sub.ServeJetStream(js))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cc.Stop()
+
+	for _, data := range []string{`{"id":1}`, `{"id":2}`} {
+		if _, err := js.Publish(ctx, "orders.created", []byte(data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	select {
+	case <-handled:
+	case <-time.After(time.Second):
+		t.Fatal("event not handled")
+	}
+
+	dl, err := dlqStream.GetMsg(ctx, 1)
+	for i := 0; err != nil && i < 20; i++ {
+		time.Sleep(50 * time.Millisecond)
+		dl, err = dlqStream.GetMsg(ctx, 1)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dl.Header.Get(natsadapter.DeadLetterStreamHeader) != "ORDERS" || dl.Header.Get(natsadapter.DeadLetterSequenceHeader) != "1" {
+		t.Errorf("unexpected dead letter headers: %v", dl.Header)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	info, err := cons.Info(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.NumAckPending != 0 || info.NumPending != 0 {
+		t.Errorf("messages not acknowledged: %+v", info)
+	}
+}
@@ -0,0 +1,68 @@
+package nats
+
+import (
+	"context"
+
+	"github.com/mcosta74/hexkit/events"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// EventPublisher is an [events.Publisher] publishing the events on a NATS connection.
+// Core NATS offers at most once delivery: use [JetStreamEventPublisher] when the
+// events must not be lost.
+type EventPublisher struct {
+	nc *nats.Conn
+}
+
+// NewEventPublisher creates an event publisher which publishes on nc.
+func NewEventPublisher(nc *nats.Conn) *EventPublisher {
+	return &EventPublisher{
+		nc: nc,
+	}
+}
+
+// Publish implements events.Publisher.
+func (p *EventPublisher) Publish(_ context.Context, evs ...events.Event) error {
+	for _, e := range evs {
+		if err := p.nc.PublishMsg(eventMsg(e)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// JetStreamEventPublisher is an [events.Publisher] publishing the events to JetStream streams
+// and waiting for their acknowledgement. The ID of the event is sent in the Nats-Msg-Id
+// header, so the stream drops the events published more than once within its
+// duplicates window.
+type JetStreamEventPublisher struct {
+	js jetstream.JetStream
+}
+
+// NewJetStreamEventPublisher creates an event publisher which publishes with js.
+func NewJetStreamEventPublisher(js jetstream.JetStream) *JetStreamEventPublisher {
+	return &JetStreamEventPublisher{
+		js: js,
+	}
+}
+
+// Publish implements events.Publisher.
+func (p *JetStreamEventPublisher) Publish(ctx context.Context, evs ...events.Event) error {
+	for _, e := range evs {
+		if _, err := p.js.PublishMsg(ctx, eventMsg(e)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func eventMsg(e events.Event) *nats.Msg {
+	msg := nats.NewMsg(e.Subject)
+	for k, v := range e.Header {
+		msg.Header.Set(k, v)
+	}
+	msg.Header.Set(jetstream.MsgIDHeader, e.ID)
+	msg.Data = e.Data
+	return msg
+}
@@ -0,0 +1,76 @@
+package nats_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+
+	natsadapter "github.com/mcosta74/hexkit/adapters/nats"
+	"github.com/mcosta74/hexkit/events"
+	kittesting "github.com/mcosta74/hexkit/internal/testing"
+)
+
+func TestEventPublisher(t *testing.T) {
+	s, c := kittesting.NewNATSServerAndConn(t)
+	defer func() {
+		s.Shutdown()
+		s.WaitForShutdown()
+	}()
+	defer c.Close()
+
+	sub, err := c.SubscribeSync("orders.>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, _ := events.New("orders.created", 1)
+	e.Header = map[string]string{"Event-Type": "created"}
+	if err := natsadapter.NewEventPublisher(c).Publish(context.Background(), e); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := sub.NextMsg(time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Header.Get(jetstream.MsgIDHeader) != e.ID || msg.Header.Get("Event-Type") != "created" || string(msg.Data) != "1" {
+		t.Errorf("unexpected message: %+v", msg)
+	}
+}
+
+func TestJetStreamEventPublisher(t *testing.T) {
+	s, c := kittesting.NewJetStreamServerAndConn(t)
+	defer func() {
+		s.Shutdown()
+		s.WaitForShutdown()
+	}()
+	defer c.Close()
+
+	ctx := context.Background()
+	js, err := jetstream.New(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: "ORDERS", Subjects: []string{"orders.>"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pub := natsadapter.NewJetStreamEventPublisher(js)
+	e, _ := events.New("orders.created", 1)
+	for range 2 {
+		if err := pub.Publish(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	info, err := stream.Info(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := uint64(1), info.State.Msgs; want != got {
+		t.Errorf("duplicate not dropped: want=%d, got=%d", want, got)
+	}
+}
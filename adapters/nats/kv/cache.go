@@ -0,0 +1,136 @@
+package kv
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/mcosta74/hexkit/requests/cache"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// CacheStore is a [cache.Store] backed by a NATS Key-Value bucket.
+//
+// Entries are stored as JSON. Cached errors are restored as [*CachedError], keeping
+// their message, HTTP status code and error code, but not their identity for errors.Is.
+// Configure a TTL on the bucket to purge entries which are no longer usable.
+type CacheStore[Resp any] struct {
+	kv jetstream.KeyValue
+}
+
+// NewCacheStore creates a cache store which saves entries in the provided bucket.
+func NewCacheStore[Resp any](kv jetstream.KeyValue) *CacheStore[Resp] {
+	return &CacheStore[Resp]{
+		kv: kv,
+	}
+}
+
+type cacheRecord[Resp any] struct {
+	Response   Resp      `json:"response"`
+	Err        string    `json:"err,omitempty"`
+	StatusCode int       `json:"status_code,omitempty"`
+	ErrorCode  string    `json:"error_code,omitempty"`
+	FreshUntil time.Time `json:"fresh_until"`
+	StaleUntil time.Time `json:"stale_until"`
+}
+
+// CachedError is an error restored from a [CacheStore]. It reports the status code
+// and the error code of the original error to the HTTP and NATS adapters.
+type CachedError struct {
+	Message string
+	Status  int
+	Code    string
+}
+
+func (e *CachedError) Error() string {
+	return e.Message
+}
+
+// StatusCode implements the StatusCoder interface of the HTTP adapter.
+func (e *CachedError) StatusCode() int {
+	if e.Status == 0 {
+		return 500
+	}
+	return e.Status
+}
+
+// ErrorCode implements the ErrorCoder interface of the NATS adapters.
+func (e *CachedError) ErrorCode() string {
+	if e.Code == "" {
+		return "500"
+	}
+	return e.Code
+}
+
+// Get implements cache.Store.
+func (s *CacheStore[Resp]) Get(ctx context.Context, key string) (cache.Entry[Resp], bool, error) {
+	kve, err := s.kv.Get(ctx, encodeKey(key))
+	if errors.Is(err, jetstream.ErrKeyNotFound) {
+		return cache.Entry[Resp]{}, false, nil
+	}
+	if err != nil {
+		return cache.Entry[Resp]{}, false, err
+	}
+
+	var rec cacheRecord[Resp]
+	if err := json.Unmarshal(kve.Value(), &rec); err != nil {
+		return cache.Entry[Resp]{}, false, err
+	}
+
+	entry := cache.Entry[Resp]{
+		Response:   rec.Response,
+		FreshUntil: rec.FreshUntil,
+		StaleUntil: rec.StaleUntil,
+	}
+	if rec.Err != "" {
+		entry.Err = &CachedError{Message: rec.Err, Status: rec.StatusCode, Code: rec.ErrorCode}
+	}
+	if !entry.Usable(time.Now()) {
+		return cache.Entry[Resp]{}, false, nil
+	}
+	return entry, true, nil
+}
+
+// Set implements cache.Store.
+func (s *CacheStore[Resp]) Set(ctx context.Context, key string, entry cache.Entry[Resp]) error {
+	rec := cacheRecord[Resp]{
+		Response:   entry.Response,
+		FreshUntil: entry.FreshUntil,
+		StaleUntil: entry.StaleUntil,
+	}
+	if entry.Err != nil {
+		rec.Err = entry.Err.Error()
+
+		var sc interface{ StatusCode() int }
+		if errors.As(entry.Err, &sc) {
+			rec.StatusCode = sc.StatusCode()
+		}
+		var ec interface{ ErrorCode() string }
+		if errors.As(entry.Err, &ec) {
+			rec.ErrorCode = ec.ErrorCode()
+		}
+	}
+
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	_, err = s.kv.Put(ctx, encodeKey(key), b)
+	return err
+}
+
+// Delete implements cache.Store.
+func (s *CacheStore[Resp]) Delete(ctx context.Context, key string) error {
+	err := s.kv.Delete(ctx, encodeKey(key))
+	if errors.Is(err, jetstream.ErrKeyNotFound) {
+		return nil
+	}
+	return err
+}
+
+// encodeKey maps an arbitrary string to a valid NATS KV key.
+func encodeKey(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
@@ -0,0 +1,120 @@
+package kv_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/mcosta74/hexkit/adapters/nats/kv"
+	kittesting "github.com/mcosta74/hexkit/internal/testing"
+	"github.com/mcosta74/hexkit/requests/cache"
+)
+
+func newBucket(t *testing.T, name string) jetstream.KeyValue {
+	t.Helper()
+
+	s, c := kittesting.NewJetStreamServerAndConn(t)
+	t.Cleanup(func() {
+		c.Close()
+		s.Shutdown()
+		s.WaitForShutdown()
+	})
+
+	js, err := jetstream.New(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bucket, err := js.CreateKeyValue(context.Background(), jetstream.KeyValueConfig{Bucket: name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bucket
+}
+
+type codedError struct{}
+
+func (codedError) Error() string     { return "gone" }
+func (codedError) StatusCode() int   { return 410 }
+func (codedError) ErrorCode() string { return "410" }
+
+func TestCacheStore(t *testing.T) {
+	ctx := context.Background()
+	store := kv.NewCacheStore[string](newBucket(t, "cache"))
+
+	t.Run("Missing", func(t *testing.T) {
+		if _, ok, err := store.Get(ctx, "missing"); ok || err != nil {
+			t.Errorf("unexpected result: ok=%v, err=%v", ok, err)
+		}
+	})
+
+	t.Run("Set and Get", func(t *testing.T) {
+		err := store.Set(ctx, "orders/1 *", cache.Entry[string]{
+			Response:   "hello",
+			FreshUntil: time.Now().Add(time.Minute),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		entry, ok, err := store.Get(ctx, "orders/1 *")
+		if !ok || err != nil {
+			t.Fatalf("unexpected result: ok=%v, err=%v", ok, err)
+		}
+		if want, got := "hello", entry.Response; want != got {
+			t.Errorf("unexpected response: want=%q, got=%q", want, got)
+		}
+	})
+
+	t.Run("Negative Entry", func(t *testing.T) {
+		_ = store.Set(ctx, "neg", cache.Entry[string]{
+			Err:        errors.New("not found"),
+			FreshUntil: time.Now().Add(time.Minute),
+		})
+
+		entry, _, _ := store.Get(ctx, "neg")
+		if entry.Err == nil || entry.Err.Error() != "not found" {
+			t.Errorf("unexpected error: want=%q, got=%v", "not found", entry.Err)
+		}
+	})
+
+	t.Run("Coded Error", func(t *testing.T) {
+		_ = store.Set(ctx, "coded", cache.Entry[string]{
+			Err:        fmt.Errorf("lookup: %w", codedError{}),
+			FreshUntil: time.Now().Add(time.Minute),
+		})
+
+		entry, _, _ := store.Get(ctx, "coded")
+		var ce *kv.CachedError
+		if !errors.As(entry.Err, &ce) {
+			t.Fatalf("unexpected error: %v", entry.Err)
+		}
+		if ce.Error() != "lookup: gone" || ce.StatusCode() != 410 || ce.ErrorCode() != "410" {
+			t.Errorf("unexpected error: %+v", ce)
+		}
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		_ = store.Set(ctx, "old", cache.Entry[string]{
+			Response:   "old",
+			FreshUntil: time.Now().Add(-time.Second),
+		})
+
+		if _, ok, _ := store.Get(ctx, "old"); ok {
+			t.Error("expected expired entry to be missing")
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if err := store.Delete(ctx, "orders/1 *"); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok, _ := store.Get(ctx, "orders/1 *"); ok {
+			t.Error("expected deleted entry to be missing")
+		}
+	})
+}
@@ -0,0 +1,3 @@
+// Package kv provides NATS Key-Value backed implementations of the toolkit stores,
+// and a watcher invoking request handlers with the changes of a bucket.
+package kv
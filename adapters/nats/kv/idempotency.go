@@ -0,0 +1,91 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/mcosta74/hexkit/requests/idempotency"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// IdempotencyStore is an [idempotency.Store] backed by a NATS Key-Value bucket.
+//
+// Reservations rely on the atomic create operation of the bucket, so the store
+// can be shared by many instances of the same service.
+// Configure a TTL on the bucket to expire the records.
+type IdempotencyStore[Resp any] struct {
+	kv jetstream.KeyValue
+}
+
+// NewIdempotencyStore creates an idempotency store which saves records in the provided bucket.
+func NewIdempotencyStore[Resp any](kv jetstream.KeyValue) *IdempotencyStore[Resp] {
+	return &IdempotencyStore[Resp]{
+		kv: kv,
+	}
+}
+
+type idempotencyRecord[Resp any] struct {
+	Fingerprint string `json:"fingerprint"`
+	Completed   bool   `json:"completed,omitempty"`
+	Response    Resp   `json:"response,omitempty"`
+}
+
+// Reserve implements idempotency.Store.
+func (s *IdempotencyStore[Resp]) Reserve(ctx context.Context, key, fingerprint string) (idempotency.Record[Resp], bool, error) {
+	b, err := json.Marshal(idempotencyRecord[Resp]{Fingerprint: fingerprint})
+	if err != nil {
+		return idempotency.Record[Resp]{}, false, err
+	}
+
+	_, err = s.kv.Create(ctx, encodeKey(key), b)
+	if err == nil {
+		return idempotency.Record[Resp]{Fingerprint: fingerprint}, true, nil
+	}
+	if !errors.Is(err, jetstream.ErrKeyExists) {
+		return idempotency.Record[Resp]{}, false, err
+	}
+
+	kve, err := s.kv.Get(ctx, encodeKey(key))
+	if errors.Is(err, jetstream.ErrKeyNotFound) {
+		// the reservation was released in the meantime
+		return s.Reserve(ctx, key, fingerprint)
+	}
+	if err != nil {
+		return idempotency.Record[Resp]{}, false, err
+	}
+
+	var rec idempotencyRecord[Resp]
+	if err := json.Unmarshal(kve.Value(), &rec); err != nil {
+		return idempotency.Record[Resp]{}, false, err
+	}
+	return idempotency.Record[Resp]{
+		Fingerprint: rec.Fingerprint,
+		Completed:   rec.Completed,
+		Response:    rec.Response,
+	}, false, nil
+}
+
+// Complete implements idempotency.Store.
+func (s *IdempotencyStore[Resp]) Complete(ctx context.Context, key string, rec idempotency.Record[Resp]) error {
+	b, err := json.Marshal(idempotencyRecord[Resp]{
+		Fingerprint: rec.Fingerprint,
+		Completed:   rec.Completed,
+		Response:    rec.Response,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.kv.Put(ctx, encodeKey(key), b)
+	return err
+}
+
+// Release implements idempotency.Store.
+func (s *IdempotencyStore[Resp]) Release(ctx context.Context, key string) error {
+	err := s.kv.Delete(ctx, encodeKey(key))
+	if errors.Is(err, jetstream.ErrKeyNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,43 @@
+package kv_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mcosta74/hexkit/adapters/nats/kv"
+	"github.com/mcosta74/hexkit/requests/idempotency"
+)
+
+func TestIdempotencyStore(t *testing.T) {
+	ctx := context.Background()
+	store := kv.NewIdempotencyStore[string](newBucket(t, "idempotency"))
+
+	if _, reserved, err := store.Reserve(ctx, "key 1", "f1"); !reserved || err != nil {
+		t.Fatalf("unexpected result: reserved=%v, err=%v", reserved, err)
+	}
+
+	rec, reserved, err := store.Reserve(ctx, "key 1", "f2")
+	if reserved || err != nil {
+		t.Fatalf("unexpected result: reserved=%v, err=%v", reserved, err)
+	}
+	if want, got := "f1", rec.Fingerprint; want != got || rec.Completed {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+
+	err = store.Complete(ctx, "key 1", idempotency.Record[string]{Fingerprint: "f1", Completed: true, Response: "done"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec, _, _ = store.Reserve(ctx, "key 1", "f1")
+	if want, got := "done", rec.Response; want != got || !rec.Completed {
+		t.Errorf("unexpected record: %+v", rec)
+	}
+
+	if err := store.Release(ctx, "key 1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, reserved, err := store.Reserve(ctx, "key 1", "f1"); !reserved || err != nil {
+		t.Errorf("unexpected result after release: reserved=%v, err=%v", reserved, err)
+	}
+}
@@ -0,0 +1,225 @@
+package kv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/mcosta74/hexkit/adapters"
+	"github.com/mcosta74/hexkit/requests"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Change is a change of a key of a bucket, decoded by [DecodeJSONChange].
+type Change[V any] struct {
+	// Key is the changed key.
+	Key string
+	// Value is the new value of the key; it's the zero value when the key was deleted or purged.
+	Value V
+	// Op is the operation which changed the key.
+	Op jetstream.KeyValueOp
+	// Revision is the revision of the change in the bucket.
+	Revision uint64
+	// Created is the time of the change.
+	Created time.Time
+}
+
+// DecodeEntryFunc extracts user-domain request object from an entry of a bucket.
+type DecodeEntryFunc[Req any] func(ctx context.Context, entry jetstream.KeyValueEntry) (request Req, err error)
+
+// DecodeJSONChange is a DecodeEntryFunc that deserializes the JSON values of the entries.
+func DecodeJSONChange[V any](_ context.Context, entry jetstream.KeyValueEntry) (Change[V], error) {
+	c := Change[V]{
+		Key:      entry.Key(),
+		Op:       entry.Operation(),
+		Revision: entry.Revision(),
+		Created:  entry.Created(),
+	}
+	if c.Op != jetstream.KeyValuePut {
+		return c, nil
+	}
+	err := json.Unmarshal(entry.Value(), &c.Value)
+	return c, err
+}
+
+// ErrWatcherClosed is returned by [Watcher.Run] when the updates stop before ctx is
+// done, e.g. because the connection was closed.
+var ErrWatcherClosed = errors.New("kv: watcher closed")
+
+// Watcher watches the keys of a bucket and invokes a request handler for each change.
+//
+// The watcher first replays the current value of each key, then the changes as they
+// happen. Processing may resume after the last revision handled, e.g. saved by a
+// checkpoint function, in which case all the changes following it are replayed.
+type Watcher[Req, Resp any] struct {
+	kv           jetstream.KeyValue
+	h            requests.Handler[Req, Resp]
+	dec          DecodeEntryFunc[Req]
+	keys         string
+	updatesOnly  bool
+	resumeAfter  uint64
+	checkpoint   func(ctx context.Context, revision uint64) error
+	snapshotDone func(ctx context.Context)
+	stopOnError  bool
+	errorHandler adapters.ErrorHandler
+
+	revision atomic.Uint64
+}
+
+// NewWatcher creates a watcher of the bucket kv, which invokes h with the entries decoded by dec.
+func NewWatcher[Req, Resp any](
+	kv jetstream.KeyValue,
+	h requests.Handler[Req, Resp],
+	dec DecodeEntryFunc[Req],
+	options ...WatcherOption[Req, Resp],
+) *Watcher[Req, Resp] {
+	w := &Watcher[Req, Resp]{
+		kv:           kv,
+		h:            h,
+		dec:          dec,
+		keys:         jetstream.AllKeys,
+		errorHandler: adapters.NewNoOpErrorHandler(),
+	}
+
+	for _, o := range options {
+		o(w)
+	}
+	return w
+}
+
+// WatcherOption sets optional parameter for the watcher.
+type WatcherOption[Req, Resp any] func(w *Watcher[Req, Resp])
+
+// WithKeys watches only the keys matching filter, which may contain wildcards (default all keys).
+func WithKeys[Req, Resp any](filter string) WatcherOption[Req, Resp] {
+	return func(w *Watcher[Req, Resp]) {
+		w.keys = filter
+	}
+}
+
+// WithUpdatesOnly skips the current values of the keys, watching only the following changes.
+func WithUpdatesOnly[Req, Resp any]() WatcherOption[Req, Resp] {
+	return func(w *Watcher[Req, Resp]) {
+		w.updatesOnly = true
+	}
+}
+
+// WithResumeAfter resumes watching after revision, replaying all the following changes.
+// A zero revision is ignored.
+func WithResumeAfter[Req, Resp any](revision uint64) WatcherOption[Req, Resp] {
+	return func(w *Watcher[Req, Resp]) {
+		w.resumeAfter = revision
+	}
+}
+
+// WithCheckpoint sets a function invoked with the revision of each change handled
+// successfully, e.g. to save it and later resume with [WithResumeAfter].
+// Errors of the function stop the watcher.
+func WithCheckpoint[Req, Resp any](f func(ctx context.Context, revision uint64) error) WatcherOption[Req, Resp] {
+	return func(w *Watcher[Req, Resp]) {
+		w.checkpoint = f
+	}
+}
+
+// WithSnapshotDone sets a function invoked once the current values of the keys
+// have been handled, e.g. to report that a read model is ready.
+func WithSnapshotDone[Req, Resp any](f func(ctx context.Context)) WatcherOption[Req, Resp] {
+	return func(w *Watcher[Req, Resp]) {
+		w.snapshotDone = f
+	}
+}
+
+// WithStopOnError stops the watcher at the first change failing to decode or handle.
+// By default, failing changes are reported to the error handler and skipped.
+func WithStopOnError[Req, Resp any]() WatcherOption[Req, Resp] {
+	return func(w *Watcher[Req, Resp]) {
+		w.stopOnError = true
+	}
+}
+
+// WithWatcherErrorHandler sets the error handler for the watcher.
+func WithWatcherErrorHandler[Req, Resp any](eh adapters.ErrorHandler) WatcherOption[Req, Resp] {
+	return func(w *Watcher[Req, Resp]) {
+		w.errorHandler = eh
+	}
+}
+
+// WithWatcherErrorLogger sets a error handler for the watcher that logs errors.
+func WithWatcherErrorLogger[Req, Resp any](logger *slog.Logger) WatcherOption[Req, Resp] {
+	return func(w *Watcher[Req, Resp]) {
+		w.errorHandler = adapters.NewSlogErrorHandler(logger)
+	}
+}
+
+// Revision returns the revision of the last change handled.
+func (w *Watcher[Req, Resp]) Revision() uint64 {
+	return w.revision.Load()
+}
+
+// Run watches the bucket until ctx is done or an error stops it. The change being
+// handled when ctx is done completes before Run returns ctx.Err(). Run returns
+// [ErrWatcherClosed] if the updates stop for another reason.
+func (w *Watcher[Req, Resp]) Run(ctx context.Context) error {
+	var opts []jetstream.WatchOpt
+	switch {
+	case w.resumeAfter > 0:
+		opts = append(opts, jetstream.ResumeFromRevision(w.resumeAfter+1))
+	case w.updatesOnly:
+		opts = append(opts, jetstream.UpdatesOnly())
+	}
+
+	kw, err := w.kv.Watch(ctx, w.keys, opts...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = kw.Stop()
+	}()
+
+	hctx := context.WithoutCancel(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case entry, ok := <-kw.Updates():
+			if !ok {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				return ErrWatcherClosed
+			}
+			if entry == nil {
+				// the current values have been delivered
+				if w.snapshotDone != nil {
+					w.snapshotDone(hctx)
+				}
+				continue
+			}
+			if err := w.handle(hctx, entry); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (w *Watcher[Req, Resp]) handle(ctx context.Context, entry jetstream.KeyValueEntry) error {
+	req, err := w.dec(ctx, entry)
+	if err == nil {
+		_, err = w.h.Handle(ctx, req)
+	}
+	if err != nil {
+		w.errorHandler.Handle(ctx, err)
+		if w.stopOnError {
+			return err
+		}
+	}
+
+	w.revision.Store(entry.Revision())
+	if err == nil && w.checkpoint != nil {
+		return w.checkpoint(ctx, entry.Revision())
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package kv_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/mcosta74/hexkit/adapters/nats/kv"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+type changes struct {
+	mu   sync.Mutex
+	seen []kv.Change[int]
+	ch   chan struct{}
+}
+
+func newChanges() *changes {
+	return &changes{ch: make(chan struct{}, 100)}
+}
+
+func (c *changes) Handle(_ context.Context, change kv.Change[int]) (struct{}, error) {
+	c.mu.Lock()
+	c.seen = append(c.seen, change)
+	c.mu.Unlock()
+	c.ch <- struct{}{}
+	return struct{}{}, nil
+}
+
+func (c *changes) wait(t *testing.T, n int) []kv.Change[int] {
+	t.Helper()
+
+	for range n {
+		select {
+		case <-c.ch:
+		case <-time.After(2 * time.Second):
+			t.Fatal("change not handled")
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.seen
+}
+
+func run(t *testing.T, w *kv.Watcher[kv.Change[int], struct{}]) (stop func() error) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+
+	return func() error {
+		cancel()
+		return <-done
+	}
+}
+
+// closedBucket is a bucket whose watchers are closed as soon as they start.
+type closedBucket struct {
+	jetstream.KeyValue
+}
+
+func (closedBucket) Watch(context.Context, string, ...jetstream.WatchOpt) (jetstream.KeyWatcher, error) {
+	updates := make(chan jetstream.KeyValueEntry)
+	close(updates)
+	return closedWatcher(updates), nil
+}
+
+type closedWatcher chan jetstream.KeyValueEntry
+
+func (w closedWatcher) Updates() <-chan jetstream.KeyValueEntry { return w }
+func (closedWatcher) Stop() error                               { return nil }
+
+func TestWatcher(t *testing.T) {
+	ctx := context.Background()
+	bucket := newBucket(t, "watcher")
+
+	if _, err := bucket.Put(ctx, "orders.1", []byte("10")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bucket.Put(ctx, "customers.1", []byte("1")); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Snapshot And Updates", func(t *testing.T) {
+		c := newChanges()
+		ready := make(chan struct{})
+		var checkpoint uint64
+		w := kv.NewWatcher(bucket, requests.Handler[kv.Change[int], struct{}](c), kv.DecodeJSONChange[int],
+			kv.WithKeys[kv.Change[int], struct{}]("orders.>"),
+			kv.WithSnapshotDone[kv.Change[int], struct{}](func(context.Context) { close(ready) }),
+			kv.WithCheckpoint[kv.Change[int], struct{}](func(_ context.Context, rev uint64) error {
+				checkpoint = rev
+				return nil
+			}),
+		)
+		stop := run(t, w)
+
+		seen := c.wait(t, 1)
+		select {
+		case <-ready:
+		case <-time.After(time.Second):
+			t.Fatal("snapshot not done")
+		}
+		if seen[0].Key != "orders.1" || seen[0].Value != 10 || seen[0].Op != jetstream.KeyValuePut {
+			t.Errorf("unexpected change: %+v", seen[0])
+		}
+
+		if err := bucket.Delete(ctx, "orders.1"); err != nil {
+			t.Fatal(err)
+		}
+		seen = c.wait(t, 1)
+		if seen[1].Op != jetstream.KeyValueDelete || seen[1].Revision != 3 {
+			t.Errorf("unexpected change: %+v", seen[1])
+		}
+
+		if err := stop(); !errors.Is(err, context.Canceled) {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if w.Revision() != 3 || checkpoint != 3 {
+			t.Errorf("unexpected revision: %d, checkpoint: %d", w.Revision(), checkpoint)
+		}
+	})
+
+	t.Run("Updates Only", func(t *testing.T) {
+		c := newChanges()
+		w := kv.NewWatcher(bucket, requests.Handler[kv.Change[int], struct{}](c), kv.DecodeJSONChange[int],
+			kv.WithUpdatesOnly[kv.Change[int], struct{}](),
+		)
+		stop := run(t, w)
+		defer stop()
+
+		time.Sleep(100 * time.Millisecond)
+		if _, err := bucket.Put(ctx, "customers.2", []byte("2")); err != nil {
+			t.Fatal(err)
+		}
+		seen := c.wait(t, 1)
+		if len(seen) != 1 || seen[0].Key != "customers.2" {
+			t.Errorf("unexpected changes: %+v", seen)
+		}
+	})
+
+	t.Run("Resume", func(t *testing.T) {
+		c := newChanges()
+		w := kv.NewWatcher(bucket, requests.Handler[kv.Change[int], struct{}](c), kv.DecodeJSONChange[int],
+			kv.WithResumeAfter[kv.Change[int], struct{}](2),
+		)
+		stop := run(t, w)
+		defer stop()
+
+		seen := c.wait(t, 2)
+		if seen[0].Revision != 3 || seen[1].Revision != 4 {
+			t.Errorf("unexpected changes: %+v", seen)
+		}
+	})
+
+	t.Run("Stop On Error", func(t *testing.T) {
+		if _, err := bucket.Put(ctx, "orders.2", []byte("not json")); err != nil {
+			t.Fatal(err)
+		}
+		w := kv.NewWatcher(bucket, requests.Handler[kv.Change[int], struct{}](newChanges()), kv.DecodeJSONChange[int],
+			kv.WithKeys[kv.Change[int], struct{}]("orders.2"),
+			kv.WithStopOnError[kv.Change[int], struct{}](),
+		)
+		if err := w.Run(ctx); err == nil {
+			t.Error("expected decode error")
+		}
+	})
+	t.Run("Closed", func(t *testing.T) {
+		w := kv.NewWatcher(closedBucket{}, requests.Handler[kv.Change[int], struct{}](newChanges()), kv.DecodeJSONChange[int])
+		if err := w.Run(ctx); !errors.Is(err, kv.ErrWatcherClosed) {
+			t.Errorf("unexpected error: want=%v, got=%v", kv.ErrWatcherClosed, err)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package micro
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+
+	natsadapter "github.com/mcosta74/hexkit/adapters/nats"
+)
+
+// claimedRequest is a request whose payload was fetched from the claim check bucket.
+type claimedRequest struct {
+	micro.Request
+	data []byte
+}
+
+func (r claimedRequest) Data() []byte {
+	return r.data
+}
+
+// claim returns msg with the payload stored with c, if it carries a claim check.
+func claim(ctx context.Context, c *natsadapter.ClaimCheck, msg micro.Request) (micro.Request, error) {
+	if msg.Headers().Get(natsadapter.ClaimCheckHeader) == "" {
+		return msg, nil
+	}
+
+	data, err := c.Claim(ctx, nats.Header(msg.Headers()), msg.Data())
+	if err != nil {
+		return nil, err
+	}
+	return claimedRequest{Request: msg, data: data}, nil
+}
+
+// WithClaimCheck makes the handler fetch the requests stored with c before validating
+// and decoding them, see [WithSchemaValidation].
+func WithClaimCheck[Req, Resp any](c *natsadapter.ClaimCheck) HandlerOption[Req, Resp] {
+	return func(s *Handler[Req, Resp]) {
+		s.claimCheck = c
+	}
+}
+
+// DecodeClaimCheckRequest wraps dec so that requests stored with c are fetched before decoding.
+// Schema validation runs before dec: use [WithClaimCheck] to validate the fetched payload.
+func DecodeClaimCheckRequest[Req any](c *natsadapter.ClaimCheck, dec DecodeRequestFunc[Req]) DecodeRequestFunc[Req] {
+	return func(ctx context.Context, msg micro.Request) (Req, error) {
+		msg, err := claim(ctx, c, msg)
+		if err != nil {
+			var zero Req
+			return zero, err
+		}
+		return dec(ctx, msg)
+	}
+}
+
+// EncodeClaimCheckResponse is an EncodeResponseFunc that serializes the response as JSON,
+// storing it with c when oversized.
+func EncodeClaimCheckResponse[Resp any](c *natsadapter.ClaimCheck) EncodeResponseFunc[Resp] {
+	return func(ctx context.Context, msg micro.Request, resp Resp) error {
+		b, err := json.Marshal(resp)
+		if err != nil {
+			return err
+		}
+
+		header := nats.Header{}
+		data, err := c.Check(ctx, header, b)
+		if err != nil {
+			return err
+		}
+		return msg.Respond(data, micro.WithHeaders(micro.Headers(header)))
+	}
+}
@@ -0,0 +1,117 @@
+package micro_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/nats-io/nats.go/micro"
+
+	natsadapter "github.com/mcosta74/hexkit/adapters/nats"
+	microadapter "github.com/mcosta74/hexkit/adapters/nats/micro"
+	kittesting "github.com/mcosta74/hexkit/internal/testing"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+func TestClaimCheck(t *testing.T) {
+	s, c := kittesting.NewJetStreamServerAndConn(t)
+	defer func() {
+		s.Shutdown()
+		s.WaitForShutdown()
+	}()
+	defer c.Close()
+
+	js, err := jetstream.New(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os, err := js.CreateObjectStore(context.Background(), jetstream.ObjectStoreConfig{Bucket: "claims"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cc := natsadapter.NewClaimCheck(os, 64)
+
+	h := microadapter.NewHandler(
+		requests.HandlerFunc[string, string](func(_ context.Context, req string) (string, error) {
+			return strings.ToUpper(req), nil
+		}),
+		microadapter.DecodeClaimCheckRequest(cc, func(_ context.Context, msg micro.Request) (string, error) {
+			var req string
+			err := json.Unmarshal(msg.Data(), &req)
+			return req, err
+		}),
+		microadapter.EncodeClaimCheckResponse[string](cc),
+	)
+	svc, err := micro.AddService(c, micro.Config{
+		Name:    "ClaimCheckTest",
+		Version: "0.0.1",
+		Endpoint: &micro.EndpointConfig{
+			Subject: "microadapter.upper",
+			Handler: h,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = svc.Stop()
+	}()
+
+	p := natsadapter.NewPublisher(c, "microadapter.upper",
+		natsadapter.EncodeClaimCheckRequest(cc, natsadapter.EncodeJSONRequest[string]),
+		natsadapter.DecodeClaimCheckResponse(cc, natsadapter.DecodeJSONResponse[string]),
+	)
+
+	for _, req := range []string{"small", strings.Repeat("a", 1000)} {
+		resp, err := p.Handle(context.Background(), req)
+		if err != nil || resp != strings.ToUpper(req) {
+			t.Errorf("unexpected response: %q, %v", resp, err)
+		}
+	}
+
+	if n, err := cc.Cleanup(context.Background(), 0); n != 2 || err != nil {
+		t.Errorf("unexpected cleanup result: n=%d, err=%v", n, err)
+	}
+
+	t.Run("Schema Validation", func(t *testing.T) {
+		h := microadapter.NewHandler(
+			requests.HandlerFunc[string, string](func(_ context.Context, req string) (string, error) {
+				return strings.ToUpper(req), nil
+			}),
+			func(_ context.Context, msg micro.Request) (string, error) {
+				var req string
+				err := json.Unmarshal(msg.Data(), &req)
+				return req, err
+			},
+			microadapter.EncodeClaimCheckResponse[string](cc),
+			microadapter.WithClaimCheck[string, string](cc),
+			microadapter.WithSchemaValidation[string, string](),
+		)
+		svc, err := micro.AddService(c, micro.Config{
+			Name:    "ClaimCheckValidationTest",
+			Version: "0.0.1",
+			Endpoint: &micro.EndpointConfig{
+				Subject: "microadapter.upper.validated",
+				Handler: h,
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			_ = svc.Stop()
+		}()
+
+		p := natsadapter.NewPublisher(c, "microadapter.upper.validated",
+			natsadapter.EncodeClaimCheckRequest(cc, natsadapter.EncodeJSONRequest[string]),
+			natsadapter.DecodeErrorResponse(natsadapter.DecodeClaimCheckResponse(cc, natsadapter.DecodeJSONResponse[string])),
+		)
+
+		req := strings.Repeat("a", 1000)
+		if resp, err := p.Handle(context.Background(), req); err != nil || resp != strings.ToUpper(req) {
+			t.Errorf("unexpected response: %q, %v", resp, err)
+		}
+	})
+}
@@ -3,7 +3,10 @@ package micro
 import (
 	"context"
 
+	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/micro"
+
+	natsadapter "github.com/mcosta74/hexkit/adapters/nats"
 )
 
 // DecodeRequestFunc extracts user-domain request object from a publisher request object.
@@ -11,3 +14,18 @@ type DecodeRequestFunc[Req any] func(ctx context.Context, msg micro.Request) (re
 
 // EncodeResponseFunc encodes the provided response object to the subscriber reply.
 type EncodeResponseFunc[Resp any] func(ctx context.Context, msg micro.Request, resp Resp) error
+
+// EncodeJSONResponse is an EncodeResponseFunc that serializes the response as JSON.
+func EncodeJSONResponse[Resp any](_ context.Context, msg micro.Request, resp Resp) error {
+	return msg.RespondJSON(resp)
+}
+
+// BindRequest is a DecodeRequestFunc which populates a struct request from the
+// payload, the subject tokens and the headers of the request, like natsadapter.BindRequest.
+func BindRequest[Req any](ctx context.Context, msg micro.Request) (Req, error) {
+	return natsadapter.BindRequest[Req](ctx, &nats.Msg{
+		Subject: msg.Subject(),
+		Header:  nats.Header(msg.Headers()),
+		Data:    msg.Data(),
+	})
+}
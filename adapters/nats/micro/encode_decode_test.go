@@ -0,0 +1,73 @@
+package micro_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/micro"
+
+	natsadapter "github.com/mcosta74/hexkit/adapters/nats"
+	microadapter "github.com/mcosta74/hexkit/adapters/nats/micro"
+	kittesting "github.com/mcosta74/hexkit/internal/testing"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+type getOrder struct {
+	Tenant string `subject:"tenant"`
+	ID     int    `subject:"id,required"`
+}
+
+func TestBindRequest(t *testing.T) {
+	s, c := kittesting.NewNATSServerAndConn(t)
+	defer func() {
+		s.Shutdown()
+		s.WaitForShutdown()
+	}()
+	defer c.Close()
+
+	p := natsadapter.MustParseSubject("orders.{tenant}.{id}.get")
+	h := microadapter.NewHandler(
+		requests.HandlerFunc[getOrder, getOrder](func(_ context.Context, req getOrder) (getOrder, error) {
+			return req, nil
+		}),
+		microadapter.BindRequest[getOrder],
+		microadapter.EncodeJSONResponse[getOrder],
+		microadapter.WithSubjectPattern[getOrder, getOrder](p),
+	)
+	svc, err := micro.AddService(c, micro.Config{
+		Name:    "BindTest",
+		Version: "0.0.1",
+		Endpoint: &micro.EndpointConfig{
+			Subject: p.Subject(),
+			Handler: h,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = svc.Stop()
+	}()
+
+	r, err := c.Request("orders.acme.42.get", nil, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got getOrder
+	if err := json.Unmarshal(r.Data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Tenant != "acme" || got.ID != 42 {
+		t.Errorf("unexpected request: %+v", got)
+	}
+
+	r, err = c.Request("orders.acme.x.get", nil, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "400", r.Header.Get(micro.ErrorCodeHeader); want != got {
+		t.Errorf("unexpected error code: want=%s, got=%s", want, got)
+	}
+}
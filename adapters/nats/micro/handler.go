@@ -5,6 +5,7 @@ import (
 	"log/slog"
 
 	"github.com/mcosta74/hexkit/adapters"
+	natsadapter "github.com/mcosta74/hexkit/adapters/nats"
 	"github.com/mcosta74/hexkit/requests"
 	"github.com/nats-io/nats.go/micro"
 )
@@ -18,6 +19,10 @@ type Handler[Req, Resp any] struct {
 	after        []HandlerResponseFunc
 	errorEncoder ErrorEncoder
 	errorHandler adapters.ErrorHandler
+	op           Operation
+	validate     func(data []byte) []string
+	claimCheck   *natsadapter.ClaimCheck
+	pattern      *natsadapter.SubjectPattern
 }
 
 // NewHandler creates a new handler, which wraps the provided request handler and implements a micro.Handler.
@@ -66,6 +71,22 @@ func WithErrorLogger[Req, Resp any](logger *slog.Logger) HandlerOption[Req, Resp
 	}
 }
 
+// WithOperation sets the documentation of the handler, reported by its Route.
+func WithOperation[Req, Resp any](op Operation) HandlerOption[Req, Resp] {
+	return func(s *Handler[Req, Resp]) {
+		s.op = op
+	}
+}
+
+// WithSubjectPattern stores in the request context the named tokens of the subject
+// of the requests matching p, see natsadapter.SubjectTokensFromContext.
+// Use p.Subject() as the subject of the endpoint.
+func WithSubjectPattern[Req, Resp any](p *natsadapter.SubjectPattern) HandlerOption[Req, Resp] {
+	return func(s *Handler[Req, Resp]) {
+		s.pattern = p
+	}
+}
+
 // WithHandlerBefore functions are executed on the NATS message object
 // before the request handler is invoked.
 func WithHandlerBefore[Req, Resp any](before ...RequestFunc) HandlerOption[Req, Resp] {
@@ -87,11 +108,14 @@ func (s *Handler[Req, Resp]) Handle(msg micro.Request) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	if s.pattern != nil {
+		ctx = s.pattern.ContextWithTokens(ctx, msg.Subject())
+	}
 	for _, f := range s.before {
 		ctx = f(ctx, msg)
 	}
 
-	request, err := s.dec(ctx, msg)
+	request, err := s.decode(ctx, msg)
 	if err != nil {
 		s.errorHandler.Handle(ctx, err)
 		if msg.Reply() != "" {
@@ -122,12 +146,38 @@ func (s *Handler[Req, Resp]) Handle(msg micro.Request) {
 	}
 }
 
+// decode fetches the claimed request payload, validates it, if enabled, and decodes it.
+func (s *Handler[Req, Resp]) decode(ctx context.Context, msg micro.Request) (Req, error) {
+	var req Req
+	if s.claimCheck != nil {
+		var err error
+		if msg, err = claim(ctx, s.claimCheck, msg); err != nil {
+			return req, err
+		}
+	}
+	if s.validate != nil {
+		if violations := s.validate(msg.Data()); len(violations) > 0 {
+			return req, &ValidationError{Violations: violations}
+		}
+	}
+	return s.dec(ctx, msg)
+}
+
 // ErrorEncoder encodes an error to the handler reply.
 type ErrorEncoder func(ctx context.Context, err error, msg micro.Request)
 
+// ErrorCoder is checked by the default error encoder. If an error implements it,
+// the code will be used when encoding the error. By default, the code is "500".
+type ErrorCoder = natsadapter.ErrorCoder
+
 // DefaultErrorEncoder is used when no error encoder is provided
 func DefaultErrorEncoder(ctx context.Context, err error, msg micro.Request) {
-	_ = msg.Error("500", err.Error(), nil)
+	_ = msg.Error(ErrorCode(err), err.Error(), nil)
+}
+
+// ErrorCode returns the micro error code for err, see natsadapter.ErrorCode.
+func ErrorCode(err error) string {
+	return natsadapter.ErrorCode(err)
 }
 
 // NoOpRequestDecoder it's a decoder that does nothing
@@ -3,6 +3,10 @@ package micro
 import (
 	"context"
 
+	natsadapter "github.com/mcosta74/hexkit/adapters/nats"
+	"github.com/mcosta74/hexkit/requests/auth"
+	"github.com/mcosta74/hexkit/requests/idempotency"
+	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/micro"
 )
 
@@ -14,3 +18,32 @@ type RequestFunc func(context.Context, micro.Request) context.Context
 // to manipulate the Publisher. HandlerResponseFunc are executed
 // after invoking the request handler but before to write the response.
 type HandlerResponseFunc func(context.Context, micro.Request) context.Context
+
+// IdempotencyKeyToContext is a RequestFunc that stores the value of the
+// Nats-Msg-Id header in the context, for use by the idempotency middleware.
+func IdempotencyKeyToContext(ctx context.Context, msg micro.Request) context.Context {
+	if key := msg.Headers().Get(nats.MsgIdHdr); key != "" {
+		return idempotency.ContextWithKey(ctx, key)
+	}
+	return ctx
+}
+
+// TokenToContext is like natsadapter.TokenToContext, for micro requests.
+func TokenToContext(header string, authn auth.TokenAuthenticator) RequestFunc {
+	f := natsadapter.TokenToContext(header, authn)
+	return func(ctx context.Context, msg micro.Request) context.Context {
+		return f(ctx, natsMsg(msg))
+	}
+}
+
+// RequestInfoToContext is like natsadapter.RequestInfoToContext, for micro requests.
+func RequestInfoToContext(serviceImportOnly bool) RequestFunc {
+	f := natsadapter.RequestInfoToContext(serviceImportOnly)
+	return func(ctx context.Context, msg micro.Request) context.Context {
+		return f(ctx, natsMsg(msg))
+	}
+}
+
+func natsMsg(msg micro.Request) *nats.Msg {
+	return &nats.Msg{Subject: msg.Subject(), Header: nats.Header(msg.Headers()), Data: msg.Data()}
+}
@@ -0,0 +1,67 @@
+package micro_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	microadapter "github.com/mcosta74/hexkit/adapters/nats/micro"
+	kittesting "github.com/mcosta74/hexkit/internal/testing"
+	"github.com/mcosta74/hexkit/requests"
+	"github.com/mcosta74/hexkit/requests/auth"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/micro"
+)
+
+func TestTokenToContext(t *testing.T) {
+	_, c := kittesting.NewNATSServerAndConn(t)
+
+	h := microadapter.NewHandler(
+		auth.New[struct{}, string]()(requests.HandlerFunc[struct{}, string](func(ctx context.Context, _ struct{}) (string, error) {
+			p, _ := auth.PrincipalFromContext(ctx)
+			return p.Subject, nil
+		})),
+		microadapter.NoOpRequestDecoder[struct{}],
+		microadapter.EncodeJSONResponse[string],
+		microadapter.WithHandlerBefore[struct{}, string](
+			microadapter.TokenToContext("Authorization", auth.StaticAPIKeys(map[string]string{"k1": "svc"})),
+		),
+	)
+
+	svc, err := micro.AddService(c, micro.Config{
+		Name:    "MicroAdapterAuth",
+		Version: "0.0.1",
+		Endpoint: &micro.EndpointConfig{
+			Subject: "microadapter.whoami",
+			Handler: h,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = svc.Stop()
+	}()
+
+	for _, tc := range []struct {
+		token string
+		code  string
+		data  string
+	}{
+		{"k1", "", `"svc"`},
+		{"k2", "401", ""},
+		{"", "401", ""},
+	} {
+		msg := nats.NewMsg("microadapter.whoami")
+		if tc.token != "" {
+			msg.Header.Set("Authorization", tc.token)
+		}
+		r, err := c.RequestMsg(msg, 3*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := r.Header.Get(micro.ErrorCodeHeader); got != tc.code || string(r.Data) != tc.data {
+			t.Errorf("unexpected reply for token %q: code=%q, data=%s", tc.token, got, r.Data)
+		}
+	}
+}
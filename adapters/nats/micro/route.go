@@ -0,0 +1,50 @@
+package micro
+
+import (
+	"reflect"
+
+	"github.com/nats-io/nats.go/micro"
+)
+
+// Operation documents a handler.
+type Operation struct {
+	// ID uniquely identifies the operation.
+	ID string
+	// Summary is a short summary of what the operation does.
+	Summary string
+	// Description is a verbose explanation of the operation behavior.
+	Description string
+	// Tags are used to group the operations.
+	Tags []string
+	// Errors maps the micro error codes the operation may return to their description.
+	Errors map[string]string
+}
+
+// Route describes a handler added to a service as an endpoint.
+type Route struct {
+	// Subject is the subject of the endpoint.
+	Subject string
+	// Queue is the queue group of the endpoint.
+	Queue string
+	// Request is the type of the request handled by the handler.
+	Request reflect.Type
+	// Response is the type of the response returned by the handler.
+	Response reflect.Type
+	// Operation is the documentation of the handler.
+	Operation Operation
+}
+
+// Route describes h as the endpoint on subject with the queue group queue
+// (micro.DefaultQueueGroup when empty).
+func (h *Handler[Req, Resp]) Route(subject, queue string) Route {
+	if queue == "" {
+		queue = micro.DefaultQueueGroup
+	}
+	return Route{
+		Subject:   subject,
+		Queue:     queue,
+		Request:   reflect.TypeFor[Req](),
+		Response:  reflect.TypeFor[Resp](),
+		Operation: h.op,
+	}
+}
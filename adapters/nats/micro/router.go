@@ -0,0 +1,30 @@
+package micro
+
+import (
+	"context"
+
+	natsadapter "github.com/mcosta74/hexkit/adapters/nats"
+	"github.com/mcosta74/hexkit/requests"
+	"github.com/nats-io/nats.go/micro"
+)
+
+// RawRequestDecoder is a DecodeRequestFunc which returns the micro request itself,
+// to expose a [requests.Router] through a [Handler].
+func RawRequestDecoder(_ context.Context, msg micro.Request) (micro.Request, error) {
+	return msg, nil
+}
+
+// HeaderKey returns a [requests.Discriminator] which selects the operation by the value of the header name.
+func HeaderKey(name string) requests.Discriminator[micro.Request] {
+	return func(_ context.Context, msg micro.Request) (string, error) {
+		return msg.Headers().Get(name), nil
+	}
+}
+
+// JSONFieldKey is like natsadapter.JSONFieldKey, for micro requests.
+func JSONFieldKey(field string) requests.Discriminator[micro.Request] {
+	key := natsadapter.JSONFieldKey(field)
+	return func(ctx context.Context, msg micro.Request) (string, error) {
+		return key(ctx, natsMsg(msg))
+	}
+}
@@ -0,0 +1,56 @@
+package micro_test
+
+import (
+	"context"
+	"testing"
+
+	microadapter "github.com/mcosta74/hexkit/adapters/nats/micro"
+	kittesting "github.com/mcosta74/hexkit/internal/testing"
+	"github.com/mcosta74/hexkit/requests"
+	"github.com/nats-io/nats.go/micro"
+)
+
+func TestRouter(t *testing.T) {
+	s, c := kittesting.NewNATSServerAndConn(t)
+	defer func() {
+		s.Shutdown()
+		s.WaitForShutdown()
+	}()
+	defer c.Close()
+
+	t.Run("Routed", func(t *testing.T) {
+		router := requests.NewRouter(microadapter.HeaderKey("Operation"))
+		requests.Route(router, "",
+			requests.HandlerFunc[string, string](func(_ context.Context, req string) (string, error) { return "hello " + req, nil }),
+			func(_ context.Context, msg micro.Request) (string, error) { return string(msg.Data()), nil },
+		)
+
+		resp := testRequest(t, c, microadapter.NewHandler(router, microadapter.RawRequestDecoder, microadapter.EncodeJSONResponse[any]))
+
+		if want, got := `"hello test"`, resp.Data; want != got {
+			t.Errorf("unexpected response: want=%q, got=%q", want, got)
+		}
+	})
+
+	t.Run("Unknown Operation", func(t *testing.T) {
+		router := requests.NewRouter(microadapter.HeaderKey("Operation"))
+
+		resp := testRequest(t, c, microadapter.NewHandler(router, microadapter.RawRequestDecoder, microadapter.EncodeJSONResponse[any]))
+
+		if want, got := `unknown operation: ""`, resp.Err; want != got {
+			t.Errorf("unexpected response: want=%q, got=%q", want, got)
+		}
+		if want, got := 400, resp.ErrCode; want != got {
+			t.Errorf("unexpected response: want=%d, got=%d", want, got)
+		}
+	})
+	t.Run("Undecodable Discriminator", func(t *testing.T) {
+		router := requests.NewRouter(microadapter.JSONFieldKey("op"))
+
+		resp := testRequest(t, c, microadapter.NewHandler(router, microadapter.RawRequestDecoder, microadapter.EncodeJSONResponse[any]))
+
+		if want, got := 400, resp.ErrCode; want != got {
+			t.Errorf("unexpected response: want=%d, got=%d (%s)", want, got, resp.Err)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package micro
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/mcosta74/hexkit/internal/jsonschema"
+	"github.com/nats-io/nats.go/micro"
+)
+
+// Metadata keys of the endpoint schemas, see [Handler.Metadata].
+const (
+	RequestSchemaMetadata  = "request_schema"
+	ResponseSchemaMetadata = "response_schema"
+)
+
+// Schemas holds the JSON Schemas of the request and the response of a handler.
+type Schemas struct {
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response"`
+}
+
+// Schemas returns the JSON Schemas of the request and the response of h,
+// derived from the Req and Resp types.
+func (h *Handler[Req, Resp]) Schemas() Schemas {
+	req, _ := json.Marshal(jsonschema.For[Req]())
+	resp, _ := json.Marshal(jsonschema.For[Resp]())
+	return Schemas{Request: req, Response: resp}
+}
+
+// Metadata returns the endpoint metadata carrying the schemas of h, published by the
+// $SRV.INFO discovery when the endpoint is added with micro.WithEndpointMetadata.
+func (h *Handler[Req, Resp]) Metadata() map[string]string {
+	schemas := h.Schemas()
+	return map[string]string{
+		RequestSchemaMetadata:  string(schemas.Request),
+		ResponseSchemaMetadata: string(schemas.Response),
+	}
+}
+
+// SchemaHandler returns a micro.Handler replying the schemas of the endpoints, by endpoint name.
+func SchemaHandler(schemas map[string]Schemas) micro.Handler {
+	b, _ := json.Marshal(schemas)
+	return micro.HandlerFunc(func(msg micro.Request) {
+		_ = msg.Respond(b)
+	})
+}
+
+// ValidationError reports a request payload not matching the schema of the request.
+type ValidationError struct {
+	// Violations describes the mismatches, each one prefixed by the JSON pointer of the value.
+	Violations []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid request: " + strings.Join(e.Violations, "; ")
+}
+
+// ErrorCode implements ErrorCoder.
+func (e *ValidationError) ErrorCode() string {
+	return "400"
+}
+
+// WithSchemaValidation validates the payload of the requests against the JSON Schema of Req
+// before decoding them, after fetching it with [WithClaimCheck]. Invalid requests are
+// replied with a [*ValidationError].
+func WithSchemaValidation[Req, Resp any]() HandlerOption[Req, Resp] {
+	schema := jsonschema.For[Req]()
+	return func(s *Handler[Req, Resp]) {
+		s.validate = schema.Validate
+	}
+}
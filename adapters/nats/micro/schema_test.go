@@ -0,0 +1,117 @@
+package micro_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	microadapter "github.com/mcosta74/hexkit/adapters/nats/micro"
+	kittesting "github.com/mcosta74/hexkit/internal/testing"
+	"github.com/mcosta74/hexkit/requests"
+	"github.com/nats-io/nats.go/micro"
+)
+
+type createOrder struct {
+	Item     string `json:"item" validate:"required"`
+	Quantity int    `json:"quantity" validate:"min=1"`
+}
+
+func TestSchemas(t *testing.T) {
+	s, c := kittesting.NewNATSServerAndConn(t)
+	defer func() {
+		s.Shutdown()
+		s.WaitForShutdown()
+	}()
+	defer c.Close()
+
+	handler := microadapter.NewHandler(
+		requests.HandlerFunc[createOrder, string](func(_ context.Context, req createOrder) (string, error) { return req.Item, nil }),
+		func(_ context.Context, msg micro.Request) (createOrder, error) {
+			var req createOrder
+			err := json.Unmarshal(msg.Data(), &req)
+			return req, err
+		},
+		microadapter.EncodeJSONResponse[string],
+		microadapter.WithSchemaValidation[createOrder, string](),
+	)
+
+	svc, err := micro.AddService(c, micro.Config{Name: "Orders", Version: "0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = svc.Stop()
+	}()
+
+	if err := svc.AddEndpoint("create", handler,
+		micro.WithEndpointSubject("orders.create"),
+		micro.WithEndpointMetadata(handler.Metadata()),
+	); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.AddEndpoint("schema",
+		microadapter.SchemaHandler(map[string]microadapter.Schemas{"create": handler.Schemas()}),
+		micro.WithEndpointSubject("orders.schema"),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	wantRequest := `{"$schema":"https://json-schema.org/draft/2020-12/schema","$ref":"#/$defs/createOrder","$defs":{"createOrder":{` +
+		`"type":"object","properties":{"item":{"type":"string"},"quantity":{"type":"integer","format":"int64","minimum":1}},"required":["item"]}}}`
+
+	t.Run("Metadata", func(t *testing.T) {
+		r, err := c.Request("$SRV.INFO.Orders", nil, 3*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var info micro.Info
+		if err := json.Unmarshal(r.Data, &info); err != nil {
+			t.Fatal(err)
+		}
+		if want, got := wantRequest, info.Endpoints[0].Metadata[microadapter.RequestSchemaMetadata]; want != got {
+			t.Errorf("unexpected request schema:\nwant=%s\ngot= %s", want, got)
+		}
+		if want, got := `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"string"}`, info.Endpoints[0].Metadata[microadapter.ResponseSchemaMetadata]; want != got {
+			t.Errorf("unexpected response schema:\nwant=%s\ngot= %s", want, got)
+		}
+	})
+
+	t.Run("Schema Subject", func(t *testing.T) {
+		r, err := c.Request("orders.schema", nil, 3*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var schemas map[string]microadapter.Schemas
+		if err := json.Unmarshal(r.Data, &schemas); err != nil {
+			t.Fatal(err)
+		}
+		if want, got := wantRequest, string(schemas["create"].Request); want != got {
+			t.Errorf("unexpected request schema:\nwant=%s\ngot= %s", want, got)
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		r, err := c.Request("orders.create", []byte(`{"quantity":0}`), 3*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if want, got := "400", r.Header.Get(micro.ErrorCodeHeader); want != got {
+			t.Errorf("unexpected error code: want=%q, got=%q", want, got)
+		}
+		if want, got := `invalid request: /: missing required property "item"; /quantity: must be greater than or equal to 1`, r.Header.Get(micro.ErrorHeader); want != got {
+			t.Errorf("unexpected error: want=%q, got=%q", want, got)
+		}
+
+		r, err = c.Request("orders.create", []byte(`{"item":"widget","quantity":1}`), 3*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := `"widget"`, string(r.Data); want != got {
+			t.Errorf("unexpected response: want=%s, got=%s", want, got)
+		}
+	})
+}
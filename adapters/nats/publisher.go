@@ -0,0 +1,131 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// EncodeRequestFunc encodes the provided request object into the NATS message of a publisher.
+type EncodeRequestFunc[Req any] func(ctx context.Context, msg *nats.Msg, req Req) error
+
+// DecodeResponseFunc extracts user-domain response object from a NATS reply.
+type DecodeResponseFunc[Resp any] func(ctx context.Context, msg *nats.Msg) (response Resp, err error)
+
+// PublisherRequestFunc may take information from the request context and use it to
+// manipulate the NATS message of a publisher, e.g. adding headers. PublisherRequestFuncs
+// are executed after encoding the request but before sending it.
+type PublisherRequestFunc func(context.Context, *nats.Msg) context.Context
+
+// PublisherResponseFunc may take information from a NATS reply and put it into
+// the request context. PublisherResponseFuncs are executed after receiving the reply
+// but before decoding it.
+type PublisherResponseFunc func(context.Context, *nats.Msg) context.Context
+
+// EncodeJSONRequest is an EncodeRequestFunc that serializes the request as JSON.
+func EncodeJSONRequest[Req any](_ context.Context, msg *nats.Msg, req Req) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	msg.Data = b
+	return nil
+}
+
+// DecodeJSONResponse is a DecodeResponseFunc that deserializes the reply as JSON.
+func DecodeJSONResponse[Resp any](_ context.Context, msg *nats.Msg) (Resp, error) {
+	var resp Resp
+	err := json.Unmarshal(msg.Data, &resp)
+	return resp, err
+}
+
+// Publisher wraps a NATS connection and provides a request handler sending the
+// requests to a subject.
+type Publisher[Req, Resp any] struct {
+	nc      *nats.Conn
+	subject string
+	enc     EncodeRequestFunc[Req]
+	dec     DecodeResponseFunc[Resp]
+	before  []PublisherRequestFunc
+	after   []PublisherResponseFunc
+	timeout time.Duration
+}
+
+// NewPublisher creates a new publisher, which sends the requests to subject and implements requests.Handler.
+func NewPublisher[Req, Resp any](
+	nc *nats.Conn,
+	subject string,
+	enc EncodeRequestFunc[Req],
+	dec DecodeResponseFunc[Resp],
+	options ...PublisherOption[Req, Resp],
+) *Publisher[Req, Resp] {
+	p := &Publisher[Req, Resp]{
+		nc:      nc,
+		subject: subject,
+		enc:     enc,
+		dec:     dec,
+		timeout: 10 * time.Second,
+	}
+
+	for _, o := range options {
+		o(p)
+	}
+	return p
+}
+
+// PublisherOption sets optional parameter for the publisher.
+type PublisherOption[Req, Resp any] func(p *Publisher[Req, Resp])
+
+// WithPublisherBefore functions are executed on the NATS message
+// before the request is sent.
+func WithPublisherBefore[Req, Resp any](before ...PublisherRequestFunc) PublisherOption[Req, Resp] {
+	return func(p *Publisher[Req, Resp]) {
+		p.before = append(p.before, before...)
+	}
+}
+
+// WithPublisherAfter functions are executed on the NATS reply
+// before it is decoded.
+func WithPublisherAfter[Req, Resp any](after ...PublisherResponseFunc) PublisherOption[Req, Resp] {
+	return func(p *Publisher[Req, Resp]) {
+		p.after = append(p.after, after...)
+	}
+}
+
+// WithPublisherTimeout sets how long to wait for the reply when the context has no deadline (default 10s).
+func WithPublisherTimeout[Req, Resp any](timeout time.Duration) PublisherOption[Req, Resp] {
+	return func(p *Publisher[Req, Resp]) {
+		p.timeout = timeout
+	}
+}
+
+// Handle implements requests.Handler.
+func (p *Publisher[Req, Resp]) Handle(ctx context.Context, req Req) (Resp, error) {
+	var resp Resp
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	msg := nats.NewMsg(p.subject)
+	if err := p.enc(ctx, msg, req); err != nil {
+		return resp, err
+	}
+	for _, f := range p.before {
+		ctx = f(ctx, msg)
+	}
+
+	reply, err := p.nc.RequestMsgWithContext(ctx, msg)
+	if err != nil {
+		return resp, err
+	}
+
+	for _, f := range p.after {
+		ctx = f(ctx, reply)
+	}
+	return p.dec(ctx, reply)
+}
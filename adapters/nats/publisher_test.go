@@ -0,0 +1,73 @@
+package nats_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	natsadapter "github.com/mcosta74/hexkit/adapters/nats"
+	kittesting "github.com/mcosta74/hexkit/internal/testing"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+func TestPublisher(t *testing.T) {
+	s, c := kittesting.NewNATSServerAndConn(t)
+	defer func() {
+		s.Shutdown()
+		s.WaitForShutdown()
+	}()
+	defer c.Close()
+
+	sub, err := c.Subscribe("natsadapter.upper", func(msg *nats.Msg) {
+		_ = msg.Respond([]byte(`"` + strings.ToUpper(msg.Header.Get("X-Prefix")+strings.Trim(string(msg.Data), `"`)) + `"`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	t.Run("Request", func(t *testing.T) {
+		var after bool
+		p := natsadapter.NewPublisher(c, "natsadapter.upper",
+			natsadapter.EncodeJSONRequest[string],
+			natsadapter.DecodeJSONResponse[string],
+			natsadapter.WithPublisherBefore[string, string](func(ctx context.Context, msg *nats.Msg) context.Context {
+				msg.Header.Set("X-Prefix", "say-")
+				return ctx
+			}),
+			natsadapter.WithPublisherAfter[string, string](func(ctx context.Context, _ *nats.Msg) context.Context {
+				after = true
+				return ctx
+			}),
+		)
+
+		var h requests.Handler[string, string] = p
+		resp, err := h.Handle(context.Background(), "hello")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "SAY-HELLO"; want != resp {
+			t.Errorf("unexpected response: want=%q, got=%q", want, resp)
+		}
+		if !after {
+			t.Error("after function not executed")
+		}
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		p := natsadapter.NewPublisher(c, "natsadapter.nobody",
+			natsadapter.EncodeJSONRequest[string],
+			natsadapter.DecodeJSONResponse[string],
+			natsadapter.WithPublisherTimeout[string, string](50*time.Millisecond),
+		)
+
+		_, err := p.Handle(context.Background(), "hello")
+		if !errors.Is(err, nats.ErrNoResponders) && !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
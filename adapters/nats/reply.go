@@ -0,0 +1,52 @@
+package nats
+
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Reply is the reply to a request received by a [Subscriber]. The after functions add
+// headers to it, the encoders fill it with data, then the subscriber publishes it with
+// msg.RespondMsg.
+type Reply struct {
+	*nats.Msg
+
+	nc        *nats.Conn
+	published bool
+}
+
+func newReply(nc *nats.Conn, msg *nats.Msg) *Reply {
+	return &Reply{
+		Msg: nats.NewMsg(msg.Reply),
+		nc:  nc,
+	}
+}
+
+// AdaptEncodeResponseFunc adapts an EncodeResponseFunc, which publishes the response
+// itself, to an EncodeReplyFunc. The headers of the reply are not sent.
+func AdaptEncodeResponseFunc[Resp any](enc EncodeResponseFunc[Resp]) EncodeReplyFunc[Resp] {
+	return func(ctx context.Context, reply *Reply, resp Resp) error {
+		if err := enc(ctx, reply.Subject, reply.nc, resp); err != nil {
+			return err
+		}
+		reply.published = true
+		return nil
+	}
+}
+
+// AdaptSubscriberResponseFunc adapts a SubscriberResponseFunc to a ReplyFunc.
+func AdaptSubscriberResponseFunc(f SubscriberResponseFunc) ReplyFunc {
+	return func(ctx context.Context, reply *Reply) context.Context {
+		return f(ctx, reply.nc)
+	}
+}
+
+// AdaptErrorEncoder adapts an ErrorEncoder, which publishes the error itself, to an
+// ErrorReplyEncoder. The headers of the reply are not sent.
+func AdaptErrorEncoder(ee ErrorEncoder) ErrorReplyEncoder {
+	return func(ctx context.Context, err error, reply *Reply) {
+		reply.published = true
+		ee(ctx, err, reply.Subject, reply.nc)
+	}
+}
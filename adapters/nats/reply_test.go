@@ -0,0 +1,121 @@
+package nats_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	natsadapter "github.com/mcosta74/hexkit/adapters/nats"
+	kittesting "github.com/mcosta74/hexkit/internal/testing"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+func TestReply(t *testing.T) {
+	_, c := kittesting.NewNATSServerAndConn(t)
+
+	request := func(t *testing.T, h *natsadapter.Subscriber[struct{}, string]) *nats.Msg {
+		t.Helper()
+
+		sub, err := c.Subscribe("natsadapter.reply", h.ServeMsg(c))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sub.Unsubscribe()
+
+		msg, err := c.Request("natsadapter.reply", nil, 3*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return msg
+	}
+
+	setHeader := func(ctx context.Context, reply *natsadapter.Reply) context.Context {
+		reply.Header.Set("Content-Type", "application/json")
+		return ctx
+	}
+
+	t.Run("Headers", func(t *testing.T) {
+		h := natsadapter.NewSubscriber(
+			requests.HandlerFunc[struct{}, string](func(context.Context, struct{}) (string, error) { return "hello", nil }),
+			natsadapter.NoOpRequestDecoder[struct{}],
+			natsadapter.EncodeJSONResponse[string],
+			natsadapter.WithSubscriberAfter[struct{}, string](setHeader),
+		)
+
+		msg := request(t, h)
+
+		if want, got := "application/json", msg.Header.Get("Content-Type"); want != got {
+			t.Errorf("unexpected header: want=%q, got=%q", want, got)
+		}
+		if want, got := `"hello"`, string(msg.Data); want != got {
+			t.Errorf("unexpected data: want=%s, got=%s", want, got)
+		}
+	})
+
+	t.Run("Error Encoder", func(t *testing.T) {
+		h := natsadapter.NewSubscriber(
+			requests.HandlerFunc[struct{}, string](func(context.Context, struct{}) (string, error) { return "", errors.New("fail") }),
+			natsadapter.NoOpRequestDecoder[struct{}],
+			natsadapter.EncodeJSONResponse[string],
+			natsadapter.WithErrorEncoder[struct{}, string](func(_ context.Context, err error, reply *natsadapter.Reply) {
+				reply.Header.Set("X-Error", err.Error())
+			}),
+		)
+
+		msg := request(t, h)
+
+		if want, got := "fail", msg.Header.Get("X-Error"); want != got {
+			t.Errorf("unexpected header: want=%q, got=%q", want, got)
+		}
+	})
+
+	t.Run("Error After Response Headers", func(t *testing.T) {
+		h := natsadapter.NewSubscriber(
+			requests.HandlerFunc[struct{}, string](func(context.Context, struct{}) (string, error) { return "hello", nil }),
+			natsadapter.NoOpRequestDecoder[struct{}],
+			func(_ context.Context, reply *natsadapter.Reply, _ string) error {
+				reply.Data = []byte("partial")
+				return errors.New("encoding failed")
+			},
+			natsadapter.WithSubscriberAfter[struct{}, string](setHeader),
+		)
+
+		msg := request(t, h)
+
+		if got := msg.Header.Get("Content-Type"); got != "" {
+			t.Errorf("unexpected response header in the error reply: %q", got)
+		}
+		if want, got := `{"err":"encoding failed"}`, string(msg.Data); want != got {
+			t.Errorf("unexpected data: want=%s, got=%s", want, got)
+		}
+	})
+
+	t.Run("Adapted", func(t *testing.T) {
+		called := false
+		h := natsadapter.NewSubscriber(
+			requests.HandlerFunc[struct{}, string](func(context.Context, struct{}) (string, error) { return "hello", nil }),
+			natsadapter.NoOpRequestDecoder[struct{}],
+			natsadapter.AdaptEncodeResponseFunc(func(_ context.Context, reply string, nc *nats.Conn, resp string) error {
+				b, _ := json.Marshal(resp)
+				return nc.Publish(reply, b)
+			}),
+			natsadapter.WithSubscriberAfter[struct{}, string](natsadapter.AdaptSubscriberResponseFunc(func(ctx context.Context, _ *nats.Conn) context.Context {
+				called = true
+				return ctx
+			})),
+		)
+
+		msg := request(t, h)
+
+		if !called {
+			t.Error("response func not called")
+		}
+		if want, got := `"hello"`, string(msg.Data); want != got {
+			t.Errorf("unexpected data: want=%s, got=%s", want, got)
+		}
+	})
+}
@@ -3,6 +3,7 @@ package nats
 import (
 	"context"
 
+	"github.com/mcosta74/hexkit/requests/idempotency"
 	"github.com/nats-io/nats.go"
 )
 
@@ -10,7 +11,20 @@ import (
 // the request context. In Servers, RequestFuncs are executed before to invoke the request handler.
 type RequestFunc func(context.Context, *nats.Msg) context.Context
 
+// ReplyFunc may take information from the request context and use it to
+// manipulate the reply, e.g. adding headers. ReplyFuncs are executed
+// after invoking the request handler but before to encode the response.
+type ReplyFunc func(context.Context, *Reply) context.Context
+
 // SubscriberResponseFunc may take information from the request context and use it
-// to manipulate the Publisher. SubscriberResponseFuncs are executed
-// after invoking the request handler but before to write the response.
+// to manipulate the Publisher. Use [AdaptSubscriberResponseFunc] to use it with a [Subscriber].
 type SubscriberResponseFunc func(context.Context, *nats.Conn) context.Context
+
+// IdempotencyKeyToContext is a RequestFunc that stores the value of the
+// Nats-Msg-Id header in the context, for use by the idempotency middleware.
+func IdempotencyKeyToContext(ctx context.Context, msg *nats.Msg) context.Context {
+	if key := msg.Header.Get(nats.MsgIdHdr); key != "" {
+		return idempotency.ContextWithKey(ctx, key)
+	}
+	return ctx
+}
@@ -0,0 +1,40 @@
+package nats
+
+import "reflect"
+
+// Operation documents a subscriber.
+type Operation struct {
+	// ID uniquely identifies the operation.
+	ID string
+	// Summary is a short summary of what the operation does.
+	Summary string
+	// Description is a verbose explanation of the operation behavior.
+	Description string
+	// Tags are used to group the operations.
+	Tags []string
+}
+
+// Route describes a subscriber subscribed to a subject.
+type Route struct {
+	// Subject is the subject of the subscription; it may contain wildcards.
+	Subject string
+	// Queue is the queue group of the subscription, if any.
+	Queue string
+	// Request is the type of the request handled by the subscriber.
+	Request reflect.Type
+	// Response is the type of the response returned by the subscriber.
+	Response reflect.Type
+	// Operation is the documentation of the subscriber.
+	Operation Operation
+}
+
+// Route describes s as subscribed to subject with the queue group queue.
+func (s *Subscriber[Req, Resp]) Route(subject, queue string) Route {
+	return Route{
+		Subject:   subject,
+		Queue:     queue,
+		Request:   reflect.TypeFor[Req](),
+		Response:  reflect.TypeFor[Resp](),
+		Operation: s.op,
+	}
+}
@@ -0,0 +1,35 @@
+package nats
+
+import (
+	"context"
+
+	"github.com/mcosta74/hexkit/internal/jsonutil"
+	"github.com/mcosta74/hexkit/requests"
+	"github.com/nats-io/nats.go"
+)
+
+// RawMsgDecoder is a DecodeRequestFunc which returns the NATS message itself,
+// to expose a [requests.Router] through a [Subscriber].
+func RawMsgDecoder(_ context.Context, msg *nats.Msg) (*nats.Msg, error) {
+	return msg, nil
+}
+
+// HeaderKey returns a [requests.Discriminator] which selects the operation by the value of the header name.
+func HeaderKey(name string) requests.Discriminator[*nats.Msg] {
+	return func(_ context.Context, msg *nats.Msg) (string, error) {
+		return msg.Header.Get(name), nil
+	}
+}
+
+// JSONFieldKey returns a [requests.Discriminator] which selects the operation by the value
+// of the top-level string field of the JSON payload. Undecodable payloads are reported
+// with the code "400".
+func JSONFieldKey(field string) requests.Discriminator[*nats.Msg] {
+	return func(_ context.Context, msg *nats.Msg) (string, error) {
+		key, err := jsonutil.StringField(msg.Data, field)
+		if err != nil {
+			return "", &codeError{code: "400", err: err}
+		}
+		return key, nil
+	}
+}
@@ -0,0 +1,78 @@
+package nats_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	natsadapter "github.com/mcosta74/hexkit/adapters/nats"
+	kittesting "github.com/mcosta74/hexkit/internal/testing"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+func TestRouter(t *testing.T) {
+	s, c := kittesting.NewNATSServerAndConn(t)
+	defer func() {
+		s.Shutdown()
+		s.WaitForShutdown()
+	}()
+	defer c.Close()
+
+	router := requests.NewRouter(natsadapter.HeaderKey("Operation"))
+	requests.Route(router, "upper",
+		requests.HandlerFunc[string, Response](func(_ context.Context, req string) (Response, error) {
+			return Response{Data: "HELLO " + req}, nil
+		}),
+		func(_ context.Context, msg *nats.Msg) (string, error) { return string(msg.Data), nil },
+	)
+
+	sub, err := c.Subscribe("natsadapter.router", natsadapter.NewSubscriber(
+		router,
+		natsadapter.RawMsgDecoder,
+		natsadapter.EncodeJSONResponse[any],
+	).ServeMsg(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	request := func(op string) Response {
+		msg := nats.NewMsg("natsadapter.router")
+		msg.Header.Set("Operation", op)
+		msg.Data = []byte("WORLD")
+
+		r, err := c.RequestMsg(msg, 3*time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var resp Response
+		if err := json.Unmarshal(r.Data, &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	if want, got := "HELLO WORLD", request("upper").Data; want != got {
+		t.Errorf("unexpected response: want=%q, got=%q", want, got)
+	}
+	if want, got := `unknown operation: "lower"`, request("lower").Err; want != got {
+		t.Errorf("unexpected error: want=%q, got=%q", want, got)
+	}
+}
+
+func TestJSONFieldKey(t *testing.T) {
+	key := natsadapter.JSONFieldKey("op")
+
+	if op, err := key(context.Background(), &nats.Msg{Data: []byte(`{"op":"upper"}`)}); err != nil || op != "upper" {
+		t.Errorf("unexpected result: %q, %v", op, err)
+	}
+
+	_, err := key(context.Background(), &nats.Msg{Data: []byte("not json")})
+	if want, got := "400", natsadapter.ErrorCode(err); want != got {
+		t.Errorf("unexpected error code: want=%s, got=%s (%v)", want, got, err)
+	}
+}
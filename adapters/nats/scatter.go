@@ -0,0 +1,184 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// ReduceFunc merges a reply of a scatter-gather request into the accumulated result.
+// The first reply is merged into the zero value of Out. When it returns an error, the
+// result it returns is discarded and the accumulated result is kept.
+type ReduceFunc[Resp, Out any] func(ctx context.Context, acc Out, resp Resp) (Out, error)
+
+// GatherError reports the replies of a scatter-gather request that couldn't be decoded or merged.
+// It's returned together with the result merged from the other replies.
+type GatherError struct {
+	// Replies is the number of replies received, including the failed ones.
+	Replies int
+	// Errs are the errors of the failed replies.
+	Errs []error
+}
+
+func (e *GatherError) Error() string {
+	return fmt.Sprintf("nats: %d of %d replies failed: %v", len(e.Errs), e.Replies, errors.Join(e.Errs...))
+}
+
+func (e *GatherError) Unwrap() []error {
+	return e.Errs
+}
+
+// ScatterGather wraps a NATS connection and provides a request handler sending each request
+// to all the subscribers of a subject, and merging their replies with a [ReduceFunc].
+//
+// Replies are collected until the maximum number of replies is received, no reply arrives
+// within the idle timeout, or the deadline of the request expires. Reaching any of them
+// is not an error.
+type ScatterGather[Req, Resp, Out any] struct {
+	nc         *nats.Conn
+	subject    string
+	enc        EncodeRequestFunc[Req]
+	dec        DecodeResponseFunc[Resp]
+	reduce     ReduceFunc[Resp, Out]
+	before     []PublisherRequestFunc
+	maxReplies int
+	idle       time.Duration
+	timeout    time.Duration
+}
+
+// NewScatterGather creates a new scatter-gather publisher, which sends the requests to subject
+// and implements requests.Handler.
+func NewScatterGather[Req, Resp, Out any](
+	nc *nats.Conn,
+	subject string,
+	enc EncodeRequestFunc[Req],
+	dec DecodeResponseFunc[Resp],
+	reduce ReduceFunc[Resp, Out],
+	options ...ScatterGatherOption[Req, Resp, Out],
+) *ScatterGather[Req, Resp, Out] {
+	sg := &ScatterGather[Req, Resp, Out]{
+		nc:      nc,
+		subject: subject,
+		enc:     enc,
+		dec:     dec,
+		reduce:  reduce,
+		timeout: 10 * time.Second,
+	}
+
+	for _, o := range options {
+		o(sg)
+	}
+	return sg
+}
+
+// ScatterGatherOption sets optional parameter for the scatter-gather publisher.
+type ScatterGatherOption[Req, Resp, Out any] func(sg *ScatterGather[Req, Resp, Out])
+
+// WithScatterGatherBefore functions are executed on the NATS message
+// before the request is sent.
+func WithScatterGatherBefore[Req, Resp, Out any](before ...PublisherRequestFunc) ScatterGatherOption[Req, Resp, Out] {
+	return func(sg *ScatterGather[Req, Resp, Out]) {
+		sg.before = append(sg.before, before...)
+	}
+}
+
+// WithMaxReplies stops collecting after n replies. By default, there is no limit.
+func WithMaxReplies[Req, Resp, Out any](n int) ScatterGatherOption[Req, Resp, Out] {
+	return func(sg *ScatterGather[Req, Resp, Out]) {
+		sg.maxReplies = n
+	}
+}
+
+// WithIdleTimeout stops collecting when no reply arrives for d. By default, there is no idle timeout.
+func WithIdleTimeout[Req, Resp, Out any](d time.Duration) ScatterGatherOption[Req, Resp, Out] {
+	return func(sg *ScatterGather[Req, Resp, Out]) {
+		sg.idle = d
+	}
+}
+
+// WithScatterGatherTimeout sets how long to collect replies when the context has no deadline (default 10s).
+func WithScatterGatherTimeout[Req, Resp, Out any](timeout time.Duration) ScatterGatherOption[Req, Resp, Out] {
+	return func(sg *ScatterGather[Req, Resp, Out]) {
+		sg.timeout = timeout
+	}
+}
+
+// Handle implements requests.Handler. It returns nats.ErrNoResponders when nobody is
+// subscribed to the subject, and a [GatherError] with the merged result when some
+// replies failed.
+func (sg *ScatterGather[Req, Resp, Out]) Handle(ctx context.Context, req Req) (Out, error) {
+	var out Out
+
+	parent := ctx
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sg.timeout)
+		defer cancel()
+	}
+
+	msg := nats.NewMsg(sg.subject)
+	if err := sg.enc(ctx, msg, req); err != nil {
+		return out, err
+	}
+	for _, f := range sg.before {
+		ctx = f(ctx, msg)
+	}
+
+	msg.Reply = sg.nc.NewInbox()
+	sub, err := sg.nc.SubscribeSync(msg.Reply)
+	if err != nil {
+		return out, err
+	}
+	defer func() {
+		_ = sub.Unsubscribe()
+	}()
+
+	if err := sg.nc.PublishMsg(msg); err != nil {
+		return out, err
+	}
+
+	var (
+		replies int
+		errs    []error
+	)
+	for sg.maxReplies <= 0 || replies < sg.maxReplies {
+		reply, err := sg.next(ctx, sub)
+		if errors.Is(err, nats.ErrNoResponders) || errors.Is(err, context.Canceled) && parent.Err() != nil {
+			return out, err
+		}
+		if err != nil {
+			break
+		}
+		replies++
+
+		resp, err := sg.dec(ctx, reply)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		// keep the accumulated result when reduce fails
+		acc, err := sg.reduce(ctx, out, resp)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		out = acc
+	}
+
+	if len(errs) > 0 {
+		return out, &GatherError{Replies: replies, Errs: errs}
+	}
+	return out, nil
+}
+
+func (sg *ScatterGather[Req, Resp, Out]) next(ctx context.Context, sub *nats.Subscription) (*nats.Msg, error) {
+	if sg.idle > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sg.idle)
+		defer cancel()
+	}
+	return sub.NextMsgWithContext(ctx)
+}
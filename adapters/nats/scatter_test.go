@@ -0,0 +1,136 @@
+package nats_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	natsadapter "github.com/mcosta74/hexkit/adapters/nats"
+	kittesting "github.com/mcosta74/hexkit/internal/testing"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+func TestScatterGather(t *testing.T) {
+	s, c := kittesting.NewNATSServerAndConn(t)
+	defer func() {
+		s.Shutdown()
+		s.WaitForShutdown()
+	}()
+	defer c.Close()
+
+	for i := range 3 {
+		sub, err := c.Subscribe("natsadapter.instances", func(msg *nats.Msg) {
+			if i == 2 && string(msg.Data) == `"fail"` {
+				_ = msg.Respond([]byte("not json"))
+				return
+			}
+			_ = msg.Respond([]byte(strconv.Itoa(i)))
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer sub.Unsubscribe()
+	}
+
+	collect := func(_ context.Context, acc []int, resp int) ([]int, error) {
+		return append(acc, resp), nil
+	}
+
+	t.Run("Max Replies", func(t *testing.T) {
+		sg := natsadapter.NewScatterGather(c, "natsadapter.instances",
+			natsadapter.EncodeJSONRequest[string],
+			natsadapter.DecodeJSONResponse[int],
+			collect,
+			natsadapter.WithMaxReplies[string, int, []int](3),
+		)
+
+		var h requests.Handler[string, []int] = sg
+		start := time.Now()
+		got, err := h.Handle(context.Background(), "ok")
+		if err != nil {
+			t.Fatal(err)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, []int{0, 1, 2}) {
+			t.Errorf("unexpected result: %v", got)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("didn't stop at max replies: %v", elapsed)
+		}
+	})
+
+	t.Run("Idle Timeout", func(t *testing.T) {
+		sg := natsadapter.NewScatterGather(c, "natsadapter.instances",
+			natsadapter.EncodeJSONRequest[string],
+			natsadapter.DecodeJSONResponse[int],
+			collect,
+			natsadapter.WithIdleTimeout[string, int, []int](100*time.Millisecond),
+		)
+
+		got, err := sg.Handle(context.Background(), "ok")
+		if err != nil || len(got) != 3 {
+			t.Errorf("unexpected result: %v, %v", got, err)
+		}
+	})
+
+	t.Run("Partial Failure", func(t *testing.T) {
+		sg := natsadapter.NewScatterGather(c, "natsadapter.instances",
+			natsadapter.EncodeJSONRequest[string],
+			natsadapter.DecodeJSONResponse[int],
+			collect,
+			natsadapter.WithScatterGatherTimeout[string, int, []int](200*time.Millisecond),
+		)
+
+		got, err := sg.Handle(context.Background(), "fail")
+		var ge *natsadapter.GatherError
+		if !errors.As(err, &ge) || ge.Replies != 3 || len(ge.Errs) != 1 {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, []int{0, 1}) {
+			t.Errorf("unexpected result: %v", got)
+		}
+	})
+
+	t.Run("Reduce Failure", func(t *testing.T) {
+		sg := natsadapter.NewScatterGather(c, "natsadapter.instances",
+			natsadapter.EncodeJSONRequest[string],
+			natsadapter.DecodeJSONResponse[int],
+			func(_ context.Context, acc []int, resp int) ([]int, error) {
+				if resp == 1 {
+					return nil, errors.New("rejected")
+				}
+				return append(acc, resp), nil
+			},
+			natsadapter.WithMaxReplies[string, int, []int](3),
+		)
+
+		got, err := sg.Handle(context.Background(), "ok")
+		var ge *natsadapter.GatherError
+		if !errors.As(err, &ge) || len(ge.Errs) != 1 {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, []int{0, 2}) {
+			t.Errorf("unexpected result: %v", got)
+		}
+	})
+
+	t.Run("No Responders", func(t *testing.T) {
+		sg := natsadapter.NewScatterGather(c, "natsadapter.nobody",
+			natsadapter.EncodeJSONRequest[string],
+			natsadapter.DecodeJSONResponse[int],
+			collect,
+		)
+
+		_, err := sg.Handle(context.Background(), "ok")
+		if !errors.Is(err, nats.ErrNoResponders) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
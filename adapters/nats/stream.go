@@ -0,0 +1,347 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/mcosta74/hexkit/adapters"
+	"github.com/mcosta74/hexkit/requests"
+	"github.com/nats-io/nats.go"
+)
+
+// Headers of the chunks of a streamed reply.
+const (
+	// StreamSeqHeader carries the sequence number of a chunk, starting from 1.
+	StreamSeqHeader = "Nats-Stream-Seq"
+	// StreamEOSHeader marks the end of the stream; the message carrying it has no data.
+	StreamEOSHeader = "Nats-Stream-Eos"
+	// StreamErrorHeader carries the error ending the stream, in the end of stream message.
+	StreamErrorHeader = "Nats-Stream-Error"
+)
+
+// ErrStreamGap is returned by a [StreamPublisher] when a chunk of the reply is lost.
+var ErrStreamGap = errors.New("nats: chunk missing from stream")
+
+// StreamError is the error sent by a [StreamSubscriber] to end a stream.
+type StreamError struct {
+	Message string
+}
+
+func (e *StreamError) Error() string {
+	return e.Message
+}
+
+// EncodeChunkFunc encodes a response of a stream into the NATS message of a chunk.
+type EncodeChunkFunc[Resp any] func(ctx context.Context, msg *nats.Msg, resp Resp) error
+
+// EncodeJSONChunk is an EncodeChunkFunc that serializes the response as JSON.
+func EncodeJSONChunk[Resp any](_ context.Context, msg *nats.Msg, resp Resp) error {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	msg.Data = b
+	return nil
+}
+
+// StreamSubscriber wraps a server streaming handler and provides a nats.MsgHandler
+// replying with a message for each response of the stream.
+//
+// The chunks are published on the reply subject of the request, numbered by the
+// [StreamSeqHeader], and are followed by a message with the [StreamEOSHeader]. Every
+// window chunks, a chunk is published with a reply subject: the subscriber waits for
+// the client to acknowledge it before going on, so fast producers don't overrun slow
+// consumers.
+type StreamSubscriber[Req, Resp any] struct {
+	h            requests.ServerStreamHandler[Req, Resp]
+	dec          DecodeRequestFunc[Req]
+	enc          EncodeChunkFunc[Resp]
+	before       []RequestFunc
+	errorHandler adapters.ErrorHandler
+	window       int
+	ackTimeout   time.Duration
+}
+
+// NewStreamSubscriber creates a new stream subscriber, which wraps the provided stream handler
+// and provides a nats.MsgHandler.
+func NewStreamSubscriber[Req, Resp any](
+	h requests.ServerStreamHandler[Req, Resp],
+	dec DecodeRequestFunc[Req],
+	enc EncodeChunkFunc[Resp],
+	options ...StreamSubscriberOption[Req, Resp],
+) *StreamSubscriber[Req, Resp] {
+	s := &StreamSubscriber[Req, Resp]{
+		h:            h,
+		dec:          dec,
+		enc:          enc,
+		errorHandler: adapters.NewNoOpErrorHandler(),
+		window:       64,
+		ackTimeout:   5 * time.Second,
+	}
+
+	for _, o := range options {
+		o(s)
+	}
+	return s
+}
+
+// StreamSubscriberOption sets optional parameter for the stream subscriber.
+type StreamSubscriberOption[Req, Resp any] func(s *StreamSubscriber[Req, Resp])
+
+// WithStreamErrorHandler sets the error handler for the stream subscriber.
+func WithStreamErrorHandler[Req, Resp any](eh adapters.ErrorHandler) StreamSubscriberOption[Req, Resp] {
+	return func(s *StreamSubscriber[Req, Resp]) {
+		s.errorHandler = eh
+	}
+}
+
+// WithStreamErrorLogger sets a error handler for the stream subscriber that logs errors.
+func WithStreamErrorLogger[Req, Resp any](logger *slog.Logger) StreamSubscriberOption[Req, Resp] {
+	return func(s *StreamSubscriber[Req, Resp]) {
+		s.errorHandler = adapters.NewSlogErrorHandler(logger)
+	}
+}
+
+// WithStreamSubscriberBefore functions are executed on the NATS message object
+// before the stream handler is invoked.
+func WithStreamSubscriberBefore[Req, Resp any](before ...RequestFunc) StreamSubscriberOption[Req, Resp] {
+	return func(s *StreamSubscriber[Req, Resp]) {
+		s.before = append(s.before, before...)
+	}
+}
+
+// WithStreamFlowControl sets the number of chunks after which the subscriber waits for the
+// acknowledgement of the client, and how long it waits before abandoning the stream
+// (default 64 and 5s). A zero window disables the flow control.
+func WithStreamFlowControl[Req, Resp any](window int, ackTimeout time.Duration) StreamSubscriberOption[Req, Resp] {
+	return func(s *StreamSubscriber[Req, Resp]) {
+		s.window = window
+		s.ackTimeout = ackTimeout
+	}
+}
+
+// ServeMsg provides nats.MsgHandler. Each stream is served in its own goroutine, so
+// a slow consumer doesn't hold up the other requests of the subscription.
+func (s *StreamSubscriber[Req, Resp]) ServeMsg(nc *nats.Conn) nats.MsgHandler {
+	return func(msg *nats.Msg) {
+		if msg.Reply == "" {
+			return
+		}
+		go s.serve(nc, msg)
+	}
+}
+
+func (s *StreamSubscriber[Req, Resp]) serve(nc *nats.Conn, msg *nats.Msg) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, f := range s.before {
+		ctx = f(ctx, msg)
+	}
+
+	request, err := s.dec(ctx, msg)
+	if err != nil {
+		s.errorHandler.Handle(ctx, err)
+		s.end(ctx, nc, msg.Reply, 1, err)
+		return
+	}
+
+	seq := 1
+	for resp, err := range s.h.HandleStream(ctx, request) {
+		if err != nil {
+			s.errorHandler.Handle(ctx, err)
+			s.end(ctx, nc, msg.Reply, seq, err)
+			return
+		}
+
+		chunk := nats.NewMsg(msg.Reply)
+		if err := s.enc(ctx, chunk, resp); err != nil {
+			s.errorHandler.Handle(ctx, err)
+			s.end(ctx, nc, msg.Reply, seq, err)
+			return
+		}
+		chunk.Header.Set(StreamSeqHeader, strconv.Itoa(seq))
+
+		if err := s.publish(ctx, nc, chunk, seq); err != nil {
+			// the client is gone: stop the stream
+			s.errorHandler.Handle(ctx, err)
+			return
+		}
+		seq++
+	}
+	s.end(ctx, nc, msg.Reply, seq, nil)
+}
+
+// publish publishes a chunk, waiting for the acknowledgement at the end of each window.
+func (s *StreamSubscriber[Req, Resp]) publish(ctx context.Context, nc *nats.Conn, chunk *nats.Msg, seq int) error {
+	if s.window <= 0 || seq%s.window != 0 {
+		return nc.PublishMsg(chunk)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.ackTimeout)
+	defer cancel()
+
+	if _, err := nc.RequestMsgWithContext(ctx, chunk); err != nil {
+		return fmt.Errorf("nats: waiting for stream acknowledgement: %w", err)
+	}
+	return nil
+}
+
+// end publishes the end of stream message, with err if not nil.
+func (s *StreamSubscriber[Req, Resp]) end(ctx context.Context, nc *nats.Conn, reply string, seq int, err error) {
+	eos := nats.NewMsg(reply)
+	eos.Header.Set(StreamSeqHeader, strconv.Itoa(seq))
+	eos.Header.Set(StreamEOSHeader, "true")
+	if err != nil {
+		eos.Header.Set(StreamErrorHeader, err.Error())
+	}
+
+	if err := nc.PublishMsg(eos); err != nil {
+		s.errorHandler.Handle(ctx, err)
+	}
+}
+
+// StreamPublisher wraps a NATS connection and provides a server streaming handler sending
+// the requests to a subject served by a [StreamSubscriber], and yielding the chunks of the reply.
+type StreamPublisher[Req, Resp any] struct {
+	nc      *nats.Conn
+	subject string
+	enc     EncodeRequestFunc[Req]
+	dec     DecodeResponseFunc[Resp]
+	before  []PublisherRequestFunc
+	timeout time.Duration
+}
+
+// NewStreamPublisher creates a new stream publisher, which sends the requests to subject
+// and implements requests.ServerStreamHandler.
+func NewStreamPublisher[Req, Resp any](
+	nc *nats.Conn,
+	subject string,
+	enc EncodeRequestFunc[Req],
+	dec DecodeResponseFunc[Resp],
+	options ...StreamPublisherOption[Req, Resp],
+) *StreamPublisher[Req, Resp] {
+	p := &StreamPublisher[Req, Resp]{
+		nc:      nc,
+		subject: subject,
+		enc:     enc,
+		dec:     dec,
+		timeout: 10 * time.Second,
+	}
+
+	for _, o := range options {
+		o(p)
+	}
+	return p
+}
+
+// StreamPublisherOption sets optional parameter for the stream publisher.
+type StreamPublisherOption[Req, Resp any] func(p *StreamPublisher[Req, Resp])
+
+// WithStreamPublisherBefore functions are executed on the NATS message
+// before the request is sent.
+func WithStreamPublisherBefore[Req, Resp any](before ...PublisherRequestFunc) StreamPublisherOption[Req, Resp] {
+	return func(p *StreamPublisher[Req, Resp]) {
+		p.before = append(p.before, before...)
+	}
+}
+
+// WithStreamPublisherTimeout sets how long to wait for each chunk (default 10s).
+func WithStreamPublisherTimeout[Req, Resp any](timeout time.Duration) StreamPublisherOption[Req, Resp] {
+	return func(p *StreamPublisher[Req, Resp]) {
+		p.timeout = timeout
+	}
+}
+
+// HandleStream implements requests.ServerStreamHandler. The chunks are acknowledged
+// when the consumer pulls the following one, so the consumer paces the producer.
+func (p *StreamPublisher[Req, Resp]) HandleStream(ctx context.Context, req Req) iter.Seq2[Resp, error] {
+	return func(yield func(Resp, error) bool) {
+		var zero Resp
+
+		msg := nats.NewMsg(p.subject)
+		if err := p.enc(ctx, msg, req); err != nil {
+			yield(zero, err)
+			return
+		}
+		for _, f := range p.before {
+			ctx = f(ctx, msg)
+		}
+
+		msg.Reply = p.nc.NewInbox()
+		sub, err := p.nc.SubscribeSync(msg.Reply)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer func() {
+			_ = sub.Unsubscribe()
+		}()
+
+		if err := p.nc.PublishMsg(msg); err != nil {
+			yield(zero, err)
+			return
+		}
+
+		for seq := 1; ; seq++ {
+			chunk, err := p.next(ctx, sub)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			if got := chunk.Header.Get(StreamSeqHeader); got != strconv.Itoa(seq) {
+				yield(zero, fmt.Errorf("%w: want chunk %d, got %s", ErrStreamGap, seq, got))
+				return
+			}
+
+			if chunk.Header.Get(StreamEOSHeader) != "" {
+				if msg := chunk.Header.Get(StreamErrorHeader); msg != "" {
+					yield(zero, &StreamError{Message: msg})
+				}
+				return
+			}
+
+			resp, err := p.dec(ctx, chunk)
+			if !yield(resp, err) || err != nil {
+				return
+			}
+
+			if chunk.Reply != "" {
+				if err := chunk.Respond(nil); err != nil {
+					yield(zero, err)
+					return
+				}
+			}
+		}
+	}
+}
+
+func (p *StreamPublisher[Req, Resp]) next(ctx context.Context, sub *nats.Subscription) (*nats.Msg, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	msg, err := sub.NextMsgWithContext(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, nats.ErrTimeout
+	}
+	return msg, err
+}
+
+// Collect returns all the responses of the stream of req, or the first error.
+func (p *StreamPublisher[Req, Resp]) Collect(ctx context.Context, req Req) ([]Resp, error) {
+	var resps []Resp
+	for resp, err := range p.HandleStream(ctx, req) {
+		if err != nil {
+			return resps, err
+		}
+		resps = append(resps, resp)
+	}
+	return resps, nil
+}
@@ -0,0 +1,126 @@
+package nats_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"iter"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	natsadapter "github.com/mcosta74/hexkit/adapters/nats"
+	kittesting "github.com/mcosta74/hexkit/internal/testing"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+func decodeCount(_ context.Context, msg *nats.Msg) (int, error) {
+	var n int
+	err := json.Unmarshal(msg.Data, &n)
+	return n, err
+}
+
+func TestStream(t *testing.T) {
+	s, c := kittesting.NewNATSServerAndConn(t)
+	defer func() {
+		s.Shutdown()
+		s.WaitForShutdown()
+	}()
+	defer c.Close()
+
+	sub := natsadapter.NewStreamSubscriber(
+		requests.ServerStreamHandlerFunc[int, int](func(_ context.Context, n int) iter.Seq2[int, error] {
+			return func(yield func(int, error) bool) {
+				for i := 1; i <= n; i++ {
+					if !yield(i, nil) {
+						return
+					}
+				}
+				if n < 0 {
+					yield(0, errors.New("negative count"))
+				}
+			}
+		}),
+		decodeCount,
+		natsadapter.EncodeJSONChunk[int],
+		natsadapter.WithStreamFlowControl[int, int](4, time.Second),
+	)
+
+	ns, err := c.Subscribe("natsadapter.count", sub.ServeMsg(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ns.Unsubscribe()
+
+	p := natsadapter.NewStreamPublisher(c, "natsadapter.count",
+		natsadapter.EncodeJSONRequest[int],
+		natsadapter.DecodeJSONResponse[int],
+		natsadapter.WithStreamPublisherTimeout[int, int](time.Second),
+	)
+
+	t.Run("Collect", func(t *testing.T) {
+		got, err := p.Collect(context.Background(), 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 10 || got[0] != 1 || got[9] != 10 {
+			t.Errorf("unexpected responses: %v", got)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		got, err := p.Collect(context.Background(), 0)
+		if err != nil || len(got) != 0 {
+			t.Errorf("unexpected result: %v, %v", got, err)
+		}
+	})
+
+	t.Run("Slow Consumer", func(t *testing.T) {
+		var got int
+		for resp, err := range p.HandleStream(context.Background(), 9) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = resp
+			time.Sleep(5 * time.Millisecond)
+		}
+		if got != 9 {
+			t.Errorf("unexpected last response: %d", got)
+		}
+	})
+
+	t.Run("Break", func(t *testing.T) {
+		for resp, err := range p.HandleStream(context.Background(), 100) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp == 2 {
+				break
+			}
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		got, err := p.Collect(context.Background(), -1)
+		var se *natsadapter.StreamError
+		if !errors.As(err, &se) || se.Message != "negative count" {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if len(got) != 0 {
+			t.Errorf("unexpected responses: %v", got)
+		}
+	})
+
+	t.Run("Decode Error", func(t *testing.T) {
+		dp := natsadapter.NewStreamPublisher(c, "natsadapter.count",
+			func(_ context.Context, msg *nats.Msg, _ int) error { msg.Data = []byte("nope"); return nil },
+			natsadapter.DecodeJSONResponse[int],
+		)
+		_, err := dp.Collect(context.Background(), 0)
+		var se *natsadapter.StreamError
+		if !errors.As(err, &se) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
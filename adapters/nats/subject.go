@@ -0,0 +1,141 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+)
+
+// SubjectPattern is a subject with named wildcard tokens, like "orders.{tenant}.{id}.get".
+//
+// A token "{name}" matches a single token of the subject, like the "*" wildcard; a final
+// token "{name...}" matches all the remaining tokens, like the ">" wildcard. Unnamed
+// wildcards are allowed too.
+type SubjectPattern struct {
+	pattern string
+	subject string
+	names   []string
+	rest    string
+}
+
+// ParseSubject parses a subject pattern.
+func ParseSubject(pattern string) (*SubjectPattern, error) {
+	p := &SubjectPattern{pattern: pattern}
+
+	// the name of the final token may contain the dots of "..."
+	body := pattern
+	if strings.HasSuffix(pattern, "...}") {
+		// the final token starts after the last ".{", or is the whole pattern
+		if i := strings.LastIndex(pattern, ".{") + 1; i > 0 || strings.HasPrefix(pattern, "{") {
+			p.rest = pattern[i+1 : len(pattern)-4]
+			body = pattern[:i] + ">"
+		}
+	}
+
+	tokens := strings.Split(body, ".")
+	subject := make([]string, len(tokens))
+	for i, tok := range tokens {
+		name, ok := strings.CutPrefix(tok, "{")
+		if ok {
+			if name, ok = strings.CutSuffix(name, "}"); !ok || name == "" {
+				return nil, fmt.Errorf("nats: invalid token %q in subject pattern %q", tok, pattern)
+			}
+		}
+
+		switch {
+		case tok == "":
+			return nil, fmt.Errorf("nats: empty token in subject pattern %q", pattern)
+		case tok == ">" && i != len(tokens)-1:
+			return nil, fmt.Errorf("nats: %q must be the last token of subject pattern %q", tok, pattern)
+		case ok:
+			p.names = append(p.names, name)
+			subject[i] = "*"
+		default:
+			if tok == "*" {
+				p.names = append(p.names, "")
+			}
+			subject[i] = tok
+		}
+	}
+	p.subject = strings.Join(subject, ".")
+	return p, nil
+}
+
+// MustParseSubject is like [ParseSubject] but panics if the pattern is invalid.
+func MustParseSubject(pattern string) *SubjectPattern {
+	p, err := ParseSubject(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the pattern.
+func (p *SubjectPattern) String() string {
+	return p.pattern
+}
+
+// Subject returns the NATS subject to subscribe, with wildcards in place of the named tokens.
+func (p *SubjectPattern) Subject() string {
+	return p.subject
+}
+
+// Match returns the named tokens of subject, and whether subject matches the pattern.
+func (p *SubjectPattern) Match(subject string) (map[string]string, bool) {
+	want := strings.Split(p.subject, ".")
+	got := strings.Split(subject, ".")
+	if len(got) < len(want) || len(got) > len(want) && want[len(want)-1] != ">" {
+		return nil, false
+	}
+
+	tokens := make(map[string]string)
+	wildcard := 0
+	for i, tok := range want {
+		switch tok {
+		case ">":
+			if p.rest != "" {
+				tokens[p.rest] = strings.Join(got[i:], ".")
+			}
+			return tokens, true
+		case "*":
+			if name := p.names[wildcard]; name != "" {
+				tokens[name] = got[i]
+			}
+			wildcard++
+		default:
+			if tok != got[i] {
+				return nil, false
+			}
+		}
+	}
+	return tokens, true
+}
+
+// RequestFunc returns a RequestFunc storing in the context the named tokens of the
+// message subject, see [SubjectTokensFromContext].
+func (p *SubjectPattern) RequestFunc() RequestFunc {
+	return func(ctx context.Context, msg *nats.Msg) context.Context {
+		return p.ContextWithTokens(ctx, msg.Subject)
+	}
+}
+
+// ContextWithTokens returns a copy of ctx carrying the named tokens of subject.
+func (p *SubjectPattern) ContextWithTokens(ctx context.Context, subject string) context.Context {
+	tokens, ok := p.Match(subject)
+	if !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, subjectTokensContextKey, tokens)
+}
+
+type contextKey int
+
+const subjectTokensContextKey contextKey = iota
+
+// SubjectTokensFromContext returns the named tokens of the subject stored in ctx by a [SubjectPattern].
+func SubjectTokensFromContext(ctx context.Context) map[string]string {
+	tokens, _ := ctx.Value(subjectTokensContextKey).(map[string]string)
+	return tokens
+}
@@ -0,0 +1,59 @@
+package nats_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+
+	natsadapter "github.com/mcosta74/hexkit/adapters/nats"
+)
+
+func TestSubjectPattern(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		subject  string
+		wildcard string
+		tokens   map[string]string
+		match    bool
+	}{
+		{"orders.{tenant}.{id}.get", "orders.acme.42.get", "orders.*.*.get", map[string]string{"tenant": "acme", "id": "42"}, true},
+		{"orders.{tenant}.{id}.get", "orders.acme.42.put", "orders.*.*.get", nil, false},
+		{"orders.{tenant}.{id}.get", "orders.acme.get", "orders.*.*.get", nil, false},
+		{"orders.*.{id}", "orders.acme.42", "orders.*.*", map[string]string{"id": "42"}, true},
+		{"files.{path...}", "files.a.b.c", "files.>", map[string]string{"path": "a.b.c"}, true},
+		{"files.>", "files.a.b", "files.>", map[string]string{}, true},
+		{"files.{path...}", "files", "files.>", nil, false},
+		{"{rest...}", "orders.acme.42", ">", map[string]string{"rest": "orders.acme.42"}, true},
+		{"{id}", "orders.acme", "*", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.subject, func(t *testing.T) {
+			p := natsadapter.MustParseSubject(tt.pattern)
+			if want, got := tt.wildcard, p.Subject(); want != got {
+				t.Errorf("unexpected subject: want=%s, got=%s", want, got)
+			}
+
+			tokens, ok := p.Match(tt.subject)
+			if ok != tt.match || (ok && !reflect.DeepEqual(tt.tokens, tokens)) {
+				t.Errorf("unexpected match: want=%v %v, got=%v %v", tt.match, tt.tokens, ok, tokens)
+			}
+		})
+	}
+
+	for _, pattern := range []string{"orders..get", "orders.{id", "orders.{}", "files.{path...}.get", "files.>.get"} {
+		if _, err := natsadapter.ParseSubject(pattern); err == nil {
+			t.Errorf("expected error parsing %q", pattern)
+		}
+	}
+
+	t.Run("Request Func", func(t *testing.T) {
+		p := natsadapter.MustParseSubject("orders.{id}")
+		ctx := p.RequestFunc()(context.Background(), &nats.Msg{Subject: "orders.7"})
+		if want, got := "7", natsadapter.SubjectTokensFromContext(ctx)["id"]; want != got {
+			t.Errorf("unexpected token: want=%s, got=%s", want, got)
+		}
+	})
+}
@@ -14,18 +14,20 @@ import (
 type Subscriber[Req, Resp any] struct {
 	h            requests.Handler[Req, Resp]
 	dec          DecodeRequestFunc[Req]
-	enc          EncodeResponseFunc[Resp]
+	enc          EncodeReplyFunc[Resp]
 	before       []RequestFunc
-	after        []SubscriberResponseFunc
-	errorEncoder ErrorEncoder
+	after        []ReplyFunc
+	errorEncoder ErrorReplyEncoder
 	errorHandler adapters.ErrorHandler
+	op           Operation
+	pattern      *SubjectPattern
 }
 
 // NewServer creates a new subscriber, which wraps the provided request handler and provides a nats.MsgHandler.
 func NewSubscriber[Req, Resp any](
 	h requests.Handler[Req, Resp],
 	dec DecodeRequestFunc[Req],
-	enc EncodeResponseFunc[Resp],
+	enc EncodeReplyFunc[Resp],
 	options ...SubscriberOption[Req, Resp],
 
 ) *Subscriber[Req, Resp] {
@@ -47,7 +49,7 @@ func NewSubscriber[Req, Resp any](
 type SubscriberOption[Req, Resp any] func(s *Subscriber[Req, Resp])
 
 // WithErrorEncoder sets the error encoder for the subscriber.
-func WithErrorEncoder[Req, Resp any](ee ErrorEncoder) SubscriberOption[Req, Resp] {
+func WithErrorEncoder[Req, Resp any](ee ErrorReplyEncoder) SubscriberOption[Req, Resp] {
 	return func(s *Subscriber[Req, Resp]) {
 		s.errorEncoder = ee
 	}
@@ -67,6 +69,21 @@ func WithErrorLogger[Req, Resp any](logger *slog.Logger) SubscriberOption[Req, R
 	}
 }
 
+// WithOperation sets the documentation of the subscriber, reported by its Route.
+func WithOperation[Req, Resp any](op Operation) SubscriberOption[Req, Resp] {
+	return func(s *Subscriber[Req, Resp]) {
+		s.op = op
+	}
+}
+
+// WithSubjectPattern stores in the request context the named tokens of the subject
+// of the messages matching p, see [SubjectTokensFromContext]. Subscribe to p.Subject().
+func WithSubjectPattern[Req, Resp any](p *SubjectPattern) SubscriberOption[Req, Resp] {
+	return func(s *Subscriber[Req, Resp]) {
+		s.pattern = p
+	}
+}
+
 // WithSubscriberBefore functions are executed on the NATS message object
 // before the request handler is invoked.
 func WithSubscriberBefore[Req, Resp any](before ...RequestFunc) SubscriberOption[Req, Resp] {
@@ -75,9 +92,9 @@ func WithSubscriberBefore[Req, Resp any](before ...RequestFunc) SubscriberOption
 	}
 }
 
-// WithSubscriberAfter functions are executed on the HTTP response writer
-// after the request handler is invoked, but before anything is written on the client.
-func WithSubscriberAfter[Req, Resp any](after ...SubscriberResponseFunc) SubscriberOption[Req, Resp] {
+// WithSubscriberAfter functions are executed on the reply after the request
+// handler is invoked, but before the response is encoded.
+func WithSubscriberAfter[Req, Resp any](after ...ReplyFunc) SubscriberOption[Req, Resp] {
 	return func(s *Subscriber[Req, Resp]) {
 		s.after = append(s.after, after...)
 	}
@@ -89,47 +106,71 @@ func (s *Subscriber[Req, Resp]) ServeMsg(nc *nats.Conn) nats.MsgHandler {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
+		if s.pattern != nil {
+			ctx = s.pattern.ContextWithTokens(ctx, msg.Subject)
+		}
 		for _, f := range s.before {
 			ctx = f(ctx, msg)
 		}
 
+		reply := newReply(nc, msg)
+
 		request, err := s.dec(ctx, msg)
 		if err != nil {
 			s.errorHandler.Handle(ctx, err)
-			if msg.Reply != "" {
-				s.errorEncoder(ctx, err, msg.Reply, nc)
-			}
+			s.replyError(ctx, msg, reply, err)
 			return
 		}
 
 		response, err := s.h.Handle(ctx, request)
 		if err != nil {
 			s.errorHandler.Handle(ctx, err)
-			if msg.Reply != "" {
-				s.errorEncoder(ctx, err, msg.Reply, nc)
-			}
+			s.replyError(ctx, msg, reply, err)
 			return
 		}
 
 		for _, f := range s.after {
-			ctx = f(ctx, nc)
+			ctx = f(ctx, reply)
 		}
 
 		if msg.Reply != "" {
-			if err := s.enc(ctx, msg.Reply, nc, response); err != nil {
+			if err := s.enc(ctx, reply, response); err != nil {
 				s.errorHandler.Handle(ctx, err)
-				s.errorEncoder(ctx, err, msg.Reply, nc)
+				s.replyError(ctx, msg, reply, err)
 				return
 			}
+			s.respond(ctx, msg, reply)
 		}
 	}
 }
 
-// ErrorEncoder encodes an error to the subscriber reply.
-type ErrorEncoder func(ctx context.Context, err error, reply string, nc *nats.Conn)
+func (s *Subscriber[Req, Resp]) replyError(ctx context.Context, msg *nats.Msg, reply *Reply, err error) {
+	if msg.Reply == "" {
+		return
+	}
+	// drop the headers and data set for the response
+	reply.Msg = nats.NewMsg(msg.Reply)
+	s.errorEncoder(ctx, err, reply)
+	s.respond(ctx, msg, reply)
+}
+
+// respond publishes reply, unless an adapted encoder published the response itself.
+func (s *Subscriber[Req, Resp]) respond(ctx context.Context, msg *nats.Msg, reply *Reply) {
+	if reply.published {
+		return
+	}
+	reply.published = true
+	if err := msg.RespondMsg(reply.Msg); err != nil {
+		s.errorHandler.Handle(ctx, err)
+	}
+}
 
-// DefaultErrorEncoder is used when no error encoder is provided
-func DefaultErrorEncoder(ctx context.Context, err error, reply string, nc *nats.Conn) {
+// ErrorReplyEncoder encodes an error into the subscriber reply.
+type ErrorReplyEncoder func(ctx context.Context, err error, reply *Reply)
+
+// DefaultErrorEncoder is used when no error encoder is provided. It replies the error
+// in a JSON body; see [ServiceErrorEncoder] for the micro-compatible headers.
+func DefaultErrorEncoder(_ context.Context, err error, reply *Reply) {
 	response := struct {
 		Error string `json:"err,omitempty"`
 	}{
@@ -140,9 +181,13 @@ func DefaultErrorEncoder(ctx context.Context, err error, reply string, nc *nats.
 	if err != nil {
 		return
 	}
-	_ = nc.Publish(reply, b)
+	reply.Data = b
 }
 
+// ErrorEncoder encodes an error and publishes it to the subject of the subscriber reply.
+// Use [AdaptErrorEncoder] to use it with a [Subscriber].
+type ErrorEncoder func(ctx context.Context, err error, reply string, nc *nats.Conn)
+
 // NoOpRequestDecoder it's a decoder that does nothing
 func NoOpRequestDecoder[Req any](context.Context, *nats.Msg) (Req, error) {
 	var req Req
@@ -54,7 +54,7 @@ func TestSubscriber(t *testing.T) {
 		handler := natsadapter.NewSubscriber(
 			requests.HandlerFunc[struct{}, struct{}](func(context.Context, struct{}) (struct{}, error) { return struct{}{}, nil }),
 			func(context.Context, *nats.Msg) (struct{}, error) { return struct{}{}, errors.New("fail") },
-			func(context.Context, string, *nats.Conn, struct{}) error { return nil },
+			natsadapter.AdaptEncodeResponseFunc(func(context.Context, string, *nats.Conn, struct{}) error { return nil }),
 		)
 
 		resp := testRequest(t, c, handler)
@@ -67,7 +67,7 @@ func TestSubscriber(t *testing.T) {
 		handler := natsadapter.NewSubscriber(
 			requests.HandlerFunc[struct{}, struct{}](func(context.Context, struct{}) (struct{}, error) { return struct{}{}, errors.New("fail") }),
 			func(context.Context, *nats.Msg) (struct{}, error) { return struct{}{}, nil },
-			func(context.Context, string, *nats.Conn, struct{}) error { return nil },
+			natsadapter.AdaptEncodeResponseFunc(func(context.Context, string, *nats.Conn, struct{}) error { return nil }),
 		)
 
 		resp := testRequest(t, c, handler)
@@ -81,7 +81,7 @@ func TestSubscriber(t *testing.T) {
 		handler := natsadapter.NewSubscriber(
 			requests.HandlerFunc[struct{}, struct{}](func(context.Context, struct{}) (struct{}, error) { return struct{}{}, nil }),
 			func(context.Context, *nats.Msg) (struct{}, error) { return struct{}{}, nil },
-			func(context.Context, string, *nats.Conn, struct{}) error { return errors.New("fail") },
+			natsadapter.AdaptEncodeResponseFunc(func(context.Context, string, *nats.Conn, struct{}) error { return errors.New("fail") }),
 		)
 
 		resp := testRequest(t, c, handler)
@@ -95,7 +95,7 @@ func TestSubscriber(t *testing.T) {
 		handler := natsadapter.NewSubscriber(
 			requests.HandlerFunc[struct{}, struct{}](func(context.Context, struct{}) (struct{}, error) { return struct{}{}, nil }),
 			func(context.Context, *nats.Msg) (struct{}, error) { return struct{}{}, nil },
-			func(_ context.Context, reply string, nc *nats.Conn, _ struct{}) error {
+			natsadapter.AdaptEncodeResponseFunc(func(_ context.Context, reply string, nc *nats.Conn, _ struct{}) error {
 				response := struct {
 					Data string `json:"data,omitempty"`
 				}{
@@ -108,7 +108,7 @@ func TestSubscriber(t *testing.T) {
 				}
 				_ = nc.Publish(reply, b)
 				return nil
-			},
+			}),
 		)
 
 		resp := testRequest(t, c, handler)
@@ -122,8 +122,8 @@ func TestSubscriber(t *testing.T) {
 		handler := natsadapter.NewSubscriber(
 			requests.HandlerFunc[struct{}, struct{}](func(context.Context, struct{}) (struct{}, error) { return struct{}{}, errors.New("fail") }),
 			func(context.Context, *nats.Msg) (struct{}, error) { return struct{}{}, nil },
-			func(context.Context, string, *nats.Conn, struct{}) error { return nil },
-			natsadapter.WithErrorEncoder[struct{}, struct{}](func(ctx context.Context, err error, reply string, nc *nats.Conn) {
+			natsadapter.AdaptEncodeResponseFunc(func(context.Context, string, *nats.Conn, struct{}) error { return nil }),
+			natsadapter.WithErrorEncoder[struct{}, struct{}](natsadapter.AdaptErrorEncoder(func(ctx context.Context, err error, reply string, nc *nats.Conn) {
 				response := struct {
 					Error string `json:"err,omitempty"`
 				}{
@@ -135,7 +135,7 @@ func TestSubscriber(t *testing.T) {
 					return
 				}
 				_ = nc.Publish(reply, b)
-			}),
+			})),
 		)
 
 		resp := testRequest(t, c, handler)
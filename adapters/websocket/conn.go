@@ -0,0 +1,475 @@
+package websocket
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// MessageType is the type of a data message.
+type MessageType int
+
+// Message types, as defined by the frame opcodes of RFC 6455.
+const (
+	TextMessage   MessageType = 1
+	BinaryMessage MessageType = 2
+)
+
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xa
+)
+
+// Close codes, as defined by RFC 6455 section 7.4.1.
+const (
+	CloseNormalClosure      = 1000
+	CloseGoingAway          = 1001
+	CloseProtocolError      = 1002
+	CloseUnsupportedData    = 1003
+	CloseNoStatusReceived   = 1005
+	CloseInvalidPayloadData = 1007
+	ClosePolicyViolation    = 1008
+	CloseMessageTooBig      = 1009
+	CloseInternalError      = 1011
+)
+
+const (
+	acceptGUID       = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	maxControlLength = 125
+	writeWait        = 10 * time.Second
+	// closeWait is how long to wait for the peer to echo a close frame.
+	closeWait = 5 * time.Second
+)
+
+// CloseCoder is checked when an error closes a connection. If an error implements it,
+// the code is sent in the close frame. By default, the code is CloseInternalError.
+type CloseCoder interface {
+	CloseCode() int
+}
+
+// CloseError reports a connection closed with a close frame, sent by the peer or
+// because of a protocol violation.
+type CloseError struct {
+	Code   int
+	Reason string
+}
+
+func (e *CloseError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("websocket: close %d", e.Code)
+	}
+	return fmt.Sprintf("websocket: close %d: %s", e.Code, e.Reason)
+}
+
+// CloseCode implements CloseCoder.
+func (e *CloseError) CloseCode() int {
+	return e.Code
+}
+
+// DefaultMaxMessageSize is the size limit of the messages read by a [Conn] without MaxMessageSize.
+const DefaultMaxMessageSize = 32 << 20
+
+// ErrCloseSent is returned when writing on a connection after the close frame has been sent.
+var ErrCloseSent = errors.New("websocket: close sent")
+
+// Conn is a WebSocket connection.
+//
+// A connection supports one concurrent reader and multiple concurrent writers.
+type Conn struct {
+	rwc    net.Conn
+	br     *bufio.Reader
+	client bool
+
+	// MaxMessageSize limits the size of the messages read; 0 means DefaultMaxMessageSize.
+	MaxMessageSize int64
+	// IdleTimeout, if set, closes the connection when no frame is received for that long.
+	IdleTimeout time.Duration
+
+	wmu       sync.Mutex
+	closeSent bool
+}
+
+// Upgrade upgrades the HTTP server connection to the WebSocket protocol.
+// On failure, an HTTP error response is written.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "websocket: method not GET", http.StatusMethodNotAllowed)
+		return nil, errors.New("websocket: method not GET")
+	}
+	if !headerContains(r.Header, "Connection", "upgrade") || !headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "websocket: not a websocket handshake", http.StatusBadRequest)
+		return nil, errors.New("websocket: not a websocket handshake")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "websocket: unsupported version", http.StatusUpgradeRequired)
+		return nil, errors.New("websocket: unsupported version")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if b, err := base64.StdEncoding.DecodeString(key); err != nil || len(b) != 16 {
+		http.Error(w, "websocket: invalid Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, errors.New("websocket: invalid Sec-WebSocket-Key")
+	}
+
+	netConn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, "websocket: hijack not supported", http.StatusInternalServerError)
+		return nil, fmt.Errorf("websocket: hijack: %w", err)
+	}
+	_ = netConn.SetDeadline(time.Time{})
+
+	resp := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+	if _, err := netConn.Write([]byte(resp)); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+
+	return &Conn{rwc: netConn, br: brw.Reader}, nil
+}
+
+// Dial opens a client connection to the ws or wss URL u.
+func Dial(ctx context.Context, u string, header http.Header) (*Conn, error) {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		dialer  net.Dialer
+		netConn net.Conn
+	)
+	switch parsed.Scheme {
+	case "ws":
+		parsed.Scheme = "http"
+		netConn, err = dialer.DialContext(ctx, "tcp", hostPort(parsed, "80"))
+	case "wss":
+		parsed.Scheme = "https"
+		tlsDialer := tls.Dialer{NetDialer: &dialer, Config: &tls.Config{ServerName: parsed.Hostname()}}
+		netConn, err = tlsDialer.DialContext(ctx, "tcp", hostPort(parsed, "443"))
+	default:
+		return nil, fmt.Errorf("websocket: unsupported scheme %q", parsed.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = netConn.SetDeadline(deadline)
+	}
+
+	nonce := make([]byte, 16)
+	_, _ = rand.Read(nonce)
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        parsed,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     header.Clone(),
+		Host:       parsed.Host,
+	}
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+
+	if err := req.Write(netConn); err != nil {
+		netConn.Close()
+		return nil, err
+	}
+
+	br := bufio.NewReader(netConn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		netConn.Close()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != acceptKey(key) {
+		netConn.Close()
+		return nil, fmt.Errorf("websocket: bad handshake: %s", resp.Status)
+	}
+	_ = netConn.SetDeadline(time.Time{})
+
+	return &Conn{rwc: netConn, br: br, client: true}, nil
+}
+
+// ReadMessage reads the next data message. Ping frames are answered and close frames
+// are echoed; after a close frame, a [*CloseError] is returned.
+// Protocol violations are reported with a [*CloseError] too, with the code to close the
+// connection with.
+func (c *Conn) ReadMessage() (MessageType, []byte, error) {
+	var (
+		typ     MessageType
+		message []byte
+	)
+
+	for {
+		if c.IdleTimeout > 0 && !c.isCloseSent() {
+			_ = c.rwc.SetReadDeadline(time.Now().Add(c.IdleTimeout))
+		}
+
+		fin, op, payload, err := c.readFrame(int64(len(message)))
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch op {
+		case opPing:
+			// a failure writing the pong surfaces with the next reads
+			_ = c.writeFrame(opPong, payload)
+			continue
+
+		case opPong:
+			continue
+
+		case opClose:
+			ce := &CloseError{Code: CloseNoStatusReceived}
+			switch {
+			case len(payload) == 1:
+				ce = &CloseError{Code: CloseProtocolError, Reason: "invalid close payload"}
+			case len(payload) >= 2:
+				ce.Code = int(binary.BigEndian.Uint16(payload))
+				ce.Reason = string(payload[2:])
+			}
+			_ = c.WriteClose(ce.Code, "")
+			return 0, nil, ce
+
+		case opContinuation:
+			if typ == 0 {
+				return 0, nil, &CloseError{Code: CloseProtocolError, Reason: "unexpected continuation frame"}
+			}
+			message = append(message, payload...)
+
+		case opText, opBinary:
+			if typ != 0 {
+				return 0, nil, &CloseError{Code: CloseProtocolError, Reason: "expected continuation frame"}
+			}
+			typ, message = MessageType(op), payload
+
+		default:
+			return 0, nil, &CloseError{Code: CloseProtocolError, Reason: fmt.Sprintf("unknown opcode %d", op)}
+		}
+
+		if fin {
+			if typ == TextMessage && !utf8.Valid(message) {
+				return 0, nil, &CloseError{Code: CloseInvalidPayloadData, Reason: "invalid UTF-8 text"}
+			}
+			return typ, message, nil
+		}
+	}
+}
+
+func (c *Conn) readFrame(read int64) (fin bool, op byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.br, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin = header[0]&0x80 != 0
+	op = header[0] & 0x0f
+	masked := header[1]&0x80 != 0
+
+	if header[0]&0x70 != 0 {
+		return false, 0, nil, &CloseError{Code: CloseProtocolError, Reason: "reserved bits set"}
+	}
+	if masked == c.client {
+		return false, 0, nil, &CloseError{Code: CloseProtocolError, Reason: "invalid frame masking"}
+	}
+
+	length := int64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, err
+		}
+		// the most significant bit must be 0
+		if ext[0]&0x80 != 0 {
+			return false, 0, nil, &CloseError{Code: CloseProtocolError, Reason: "invalid frame length"}
+		}
+		length = int64(binary.BigEndian.Uint64(ext[:]))
+	}
+
+	if op >= opClose && (length > maxControlLength || !fin) {
+		return false, 0, nil, &CloseError{Code: CloseProtocolError, Reason: "invalid control frame"}
+	}
+	limit := c.MaxMessageSize
+	if limit <= 0 {
+		limit = DefaultMaxMessageSize
+	}
+	if op < opClose && read+length > limit {
+		return false, 0, nil, &CloseError{Code: CloseMessageTooBig, Reason: "message too big"}
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.br, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		maskBytes(mask, payload)
+	}
+	return fin, op, payload, nil
+}
+
+// WriteMessage writes a data message in a single frame.
+func (c *Conn) WriteMessage(typ MessageType, data []byte) error {
+	return c.writeFrame(byte(typ), data)
+}
+
+// WritePing writes a ping control frame.
+func (c *Conn) WritePing(data []byte) error {
+	return c.writeFrame(opPing, data)
+}
+
+// WriteClose writes a close frame with code and reason, unless it has already been sent.
+// No data can be written afterwards, and the reads time out if the peer doesn't
+// close the connection in a few seconds.
+func (c *Conn) WriteClose(code int, reason string) error {
+	var payload []byte
+	if code != CloseNoStatusReceived {
+		if len(reason) > maxControlLength-2 {
+			reason = reason[:maxControlLength-2]
+		}
+		payload = binary.BigEndian.AppendUint16(nil, uint16(code))
+		payload = append(payload, reason...)
+	}
+
+	err := c.writeFrame(opClose, payload)
+	if errors.Is(err, ErrCloseSent) {
+		return nil
+	}
+	if err == nil {
+		_ = c.rwc.SetReadDeadline(time.Now().Add(closeWait))
+	}
+	return err
+}
+
+func (c *Conn) isCloseSent() bool {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	return c.closeSent
+}
+
+// Close closes the underlying network connection without sending a close frame.
+func (c *Conn) Close() error {
+	return c.rwc.Close()
+}
+
+// SetReadDeadline sets the deadline for the reads on the underlying network connection.
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.rwc.SetReadDeadline(t)
+}
+
+func (c *Conn) writeFrame(op byte, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	if c.closeSent {
+		return ErrCloseSent
+	}
+	if op == opClose {
+		c.closeSent = true
+	}
+
+	frame := make([]byte, 0, len(payload)+14)
+	frame = append(frame, 0x80|op)
+
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+	switch n := len(payload); {
+	case n <= 125:
+		frame = append(frame, maskBit|byte(n))
+	case n <= 0xffff:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+
+	if c.client {
+		var mask [4]byte
+		_, _ = rand.Read(mask[:])
+		frame = append(frame, mask[:]...)
+		frame = append(frame, payload...)
+		maskBytes(mask, frame[len(frame)-len(payload):])
+	} else {
+		frame = append(frame, payload...)
+	}
+
+	_ = c.rwc.SetWriteDeadline(time.Now().Add(writeWait))
+	_, err := c.rwc.Write(frame)
+	return err
+}
+
+func maskBytes(mask [4]byte, b []byte) {
+	for i := range b {
+		b[i] ^= mask[i%4]
+	}
+}
+
+func acceptKey(key string) string {
+	h := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// headerContains reports whether the comma separated values of the header name contain token.
+func headerContains(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, t := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hostPort(u *url.URL, defaultPort string) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	return net.JoinHostPort(u.Hostname(), defaultPort)
+}
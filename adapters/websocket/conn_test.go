@@ -0,0 +1,215 @@
+package websocket_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mcosta74/hexkit/adapters/websocket"
+)
+
+// newEchoServer starts a server echoing the messages received on the connections.
+func newEchoServer(t *testing.T, maxMessageSize int64) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.MaxMessageSize = maxMessageSize
+
+		for {
+			typ, data, err := conn.ReadMessage()
+			if err != nil {
+				var ce *websocket.CloseError
+				if errors.As(err, &ce) {
+					_ = conn.WriteClose(ce.Code, ce.Reason)
+				}
+				return
+			}
+			if err := conn.WriteMessage(typ, data); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func dial(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	conn, err := websocket.Dial(ctx, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// rawDial opens a TCP connection to url and performs the WebSocket handshake.
+func rawDial(t *testing.T, url string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "ws://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(3 * time.Second))
+
+	req, _ := http.NewRequest("GET", "http"+strings.TrimPrefix(url, "ws"), nil)
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if err := req.Write(conn); err != nil {
+		t.Fatal(err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("unexpected status: %s", resp.Status)
+	}
+	return conn, br
+}
+
+func TestConn(t *testing.T) {
+	url := newEchoServer(t, 1<<20)
+
+	t.Run("Echo", func(t *testing.T) {
+		conn := dial(t, url)
+
+		for _, size := range []int{0, 125, 126, 70000} {
+			data := bytes.Repeat([]byte("x"), size)
+			if err := conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+				t.Fatal(err)
+			}
+
+			typ, got, err := conn.ReadMessage()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if typ != websocket.BinaryMessage || !bytes.Equal(data, got) {
+				t.Errorf("unexpected message of size %d: type=%d, size=%d", size, typ, len(got))
+			}
+		}
+	})
+
+	t.Run("Ping", func(t *testing.T) {
+		conn := dial(t, url)
+
+		if err := conn.WritePing([]byte("hi")); err != nil {
+			t.Fatal(err)
+		}
+		if err := conn.WriteMessage(websocket.TextMessage, []byte("after ping")); err != nil {
+			t.Fatal(err)
+		}
+
+		_, got, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "after ping"; want != string(got) {
+			t.Errorf("unexpected message: want=%q, got=%q", want, got)
+		}
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		conn := dial(t, url)
+
+		if err := conn.WriteClose(websocket.CloseNormalClosure, "bye"); err != nil {
+			t.Fatal(err)
+		}
+		if err := conn.WriteMessage(websocket.TextMessage, nil); !errors.Is(err, websocket.ErrCloseSent) {
+			t.Errorf("unexpected error: want=%v, got=%v", websocket.ErrCloseSent, err)
+		}
+
+		_, _, err := conn.ReadMessage()
+		var ce *websocket.CloseError
+		if !errors.As(err, &ce) || ce.Code != websocket.CloseNormalClosure {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Message Too Big", func(t *testing.T) {
+		conn := dial(t, newEchoServer(t, 10))
+
+		if err := conn.WriteMessage(websocket.TextMessage, []byte("way too big message")); err != nil {
+			t.Fatal(err)
+		}
+
+		_, _, err := conn.ReadMessage()
+		var ce *websocket.CloseError
+		if !errors.As(err, &ce) || ce.Code != websocket.CloseMessageTooBig {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Frame Length", func(t *testing.T) {
+		unlimited := newEchoServer(t, 0)
+
+		for _, tc := range []struct {
+			name   string
+			length uint64
+			code   int
+		}{
+			{"Default Limit", websocket.DefaultMaxMessageSize + 1, websocket.CloseMessageTooBig},
+			{"Huge", 1 << 62, websocket.CloseMessageTooBig},
+			{"High Bit", 1 << 63, websocket.CloseProtocolError},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				conn, br := rawDial(t, unlimited)
+
+				// a masked binary frame header announcing the length, without payload
+				frame := binary.BigEndian.AppendUint64([]byte{0x82, 0x80 | 127}, tc.length)
+				frame = append(frame, 0, 0, 0, 0)
+				if _, err := conn.Write(frame); err != nil {
+					t.Fatal(err)
+				}
+
+				var header [4]byte
+				if _, err := io.ReadFull(br, header[:]); err != nil {
+					t.Fatal(err)
+				}
+				if header[0] != 0x88 {
+					t.Fatalf("unexpected frame: %x", header)
+				}
+				if got := int(binary.BigEndian.Uint16(header[2:])); got != tc.code {
+					t.Errorf("unexpected close code: want=%d, got=%d", tc.code, got)
+				}
+			})
+		}
+	})
+
+	t.Run("Bad Handshake", func(t *testing.T) {
+		resp, err := http.Get("http" + strings.TrimPrefix(url, "ws"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		if want, got := http.StatusBadRequest, resp.StatusCode; want != got {
+			t.Errorf("unexpected status code: want=%d, got=%d", want, got)
+		}
+	})
+}
@@ -0,0 +1,6 @@
+// Package websocket provides a WebSocket binding for request handlers.
+//
+// The [Server] upgrades HTTP connections and routes the JSON [Message] envelopes received
+// on them, by type, to unary and server streaming handlers. The WebSocket protocol
+// (RFC 6455) is implemented by [Conn], without external dependencies.
+package websocket
@@ -0,0 +1,468 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mcosta74/hexkit/adapters"
+	natsadapter "github.com/mcosta74/hexkit/adapters/nats"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+// Message is the envelope of the messages exchanged on the connections of a [Server].
+//
+// Requests carry the operation Type, an ID chosen by the client and the Payload of the
+// request. Replies carry the same Type and ID, and either the Payload of the response or
+// an Error. Streams reply with a message per response and end with a message with Done set;
+// clients may cancel a stream sending a message with its ID and Cancel set.
+type Message struct {
+	Type    string          `json:"type"`
+	ID      string          `json:"id,omitempty"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+	Error   *ErrorBody      `json:"error,omitempty"`
+	Done    bool            `json:"done,omitempty"`
+	Cancel  bool            `json:"cancel,omitempty"`
+}
+
+// ErrorBody describes an error replied to a request.
+type ErrorBody struct {
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// ErrorCoder is checked when replying errors. If an error implements it, the code
+// is reported in the [ErrorBody]. It's the same interface of the NATS adapters.
+type ErrorCoder = natsadapter.ErrorCoder
+
+// ErrorCode returns the code replied for err, see natsadapter.ErrorCode.
+func ErrorCode(err error) string {
+	return natsadapter.ErrorCode(err)
+}
+
+// DecodeRequestFunc extracts user-domain request object from a message.
+type DecodeRequestFunc[Req any] func(ctx context.Context, msg Message) (request Req, err error)
+
+// DecodeJSONRequest is a DecodeRequestFunc that deserializes the JSON payload of the message.
+func DecodeJSONRequest[Req any](_ context.Context, msg Message) (Req, error) {
+	var req Req
+	if len(msg.Payload) == 0 {
+		return req, nil
+	}
+	err := json.Unmarshal(msg.Payload, &req)
+	return req, err
+}
+
+// RequestFunc may take information from the HTTP upgrade request and put it into
+// the context of the connection. RequestFuncs are executed before the upgrade.
+type RequestFunc func(context.Context, *http.Request) context.Context
+
+// StreamIDError is replied, with code "409", to the stream requests reusing the ID
+// of an active stream of the connection.
+type StreamIDError struct {
+	ID string
+}
+
+func (e *StreamIDError) Error() string {
+	return fmt.Sprintf("websocket: stream %q already active", e.ID)
+}
+
+// ErrorCode implements ErrorCoder.
+func (e *StreamIDError) ErrorCode() string {
+	return "409"
+}
+
+type streamFunc func(ctx context.Context, msg Message) (iter.Seq2[any, error], error)
+
+// Server upgrades HTTP connections to WebSocket and dispatches the messages received
+// on them to the handlers registered by type with [Handle] and [HandleStream].
+//
+// Handler errors are replied to the request; if they implement [CloseCoder], the
+// connection is closed afterwards with their close code. Handler panics are recovered
+// and replied as errors with code "500".
+type Server struct {
+	router  *requests.Router[Message]
+	mu      sync.RWMutex
+	streams map[string]streamFunc
+
+	before         []RequestFunc
+	errorHandler   adapters.ErrorHandler
+	checkOrigin    func(*http.Request) bool
+	maxConcurrency int
+	maxMessageSize int64
+	pingInterval   time.Duration
+	pongTimeout    time.Duration
+}
+
+// ServerOption sets optional parameter for the server.
+type ServerOption func(s *Server)
+
+// WithErrorHandler sets the error handler for the server.
+func WithErrorHandler(eh adapters.ErrorHandler) ServerOption {
+	return func(s *Server) {
+		s.errorHandler = eh
+	}
+}
+
+// WithErrorLogger sets a error handler for the server that logs errors.
+func WithErrorLogger(logger *slog.Logger) ServerOption {
+	return func(s *Server) {
+		s.errorHandler = adapters.NewSlogErrorHandler(logger)
+	}
+}
+
+// WithServerBefore functions are executed on the HTTP upgrade request.
+func WithServerBefore(before ...RequestFunc) ServerOption {
+	return func(s *Server) {
+		s.before = append(s.before, before...)
+	}
+}
+
+// WithCheckOrigin sets the function accepting the origin of the upgrade requests.
+// By default, only the same origin is accepted, see [SameOrigin]; use [AnyOrigin] to
+// accept all the origins, e.g. when the clients authenticate without cookies.
+func WithCheckOrigin(check func(*http.Request) bool) ServerOption {
+	return func(s *Server) {
+		s.checkOrigin = check
+	}
+}
+
+// SameOrigin accepts the upgrade requests without Origin header, as sent by non-browser
+// clients, and those whose Origin host matches the Host of the request. It prevents
+// other websites from opening connections with the cookies of the user.
+func SameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// AnyOrigin accepts all the upgrade requests.
+func AnyOrigin(*http.Request) bool {
+	return true
+}
+
+// WithMaxConcurrency sets the maximum number of messages handled concurrently on
+// each connection (default 16). When the limit is reached, the connection isn't read
+// until a handler completes.
+func WithMaxConcurrency(n int) ServerOption {
+	return func(s *Server) {
+		s.maxConcurrency = n
+	}
+}
+
+// WithMaxMessageSize sets the maximum size of the messages read (default 1 MiB); zero
+// means [DefaultMaxMessageSize]. Bigger messages close the connection with CloseMessageTooBig.
+func WithMaxMessageSize(n int64) ServerOption {
+	return func(s *Server) {
+		s.maxMessageSize = n
+	}
+}
+
+// WithKeepalive sets the interval of the pings sent to the clients, and how long
+// to wait for any frame after a ping before closing the connection (default 30s and 10s).
+// A zero interval disables the pings.
+func WithKeepalive(interval, timeout time.Duration) ServerOption {
+	return func(s *Server) {
+		s.pingInterval = interval
+		s.pongTimeout = timeout
+	}
+}
+
+// NewServer creates a new server with no handlers.
+func NewServer(options ...ServerOption) *Server {
+	s := &Server{
+		router:         requests.NewRouter(func(_ context.Context, msg Message) (string, error) { return msg.Type, nil }),
+		streams:        make(map[string]streamFunc),
+		errorHandler:   adapters.NewNoOpErrorHandler(),
+		checkOrigin:    SameOrigin,
+		maxConcurrency: 16,
+		maxMessageSize: 1 << 20,
+		pingInterval:   30 * time.Second,
+		pongTimeout:    10 * time.Second,
+	}
+
+	for _, o := range options {
+		o(s)
+	}
+	return s
+}
+
+// Handle registers h on s for the messages of type typ. The payload of the messages
+// is decoded by dec; responses are encoded as JSON payloads.
+func Handle[Req, Resp any](s *Server, typ string, h requests.Handler[Req, Resp], dec DecodeRequestFunc[Req]) {
+	requests.Route(s.router, typ, h, dec)
+}
+
+// HandleStream registers h on s for the messages of type typ, pushing each response
+// of the stream to the client. The payload of the messages is decoded by dec;
+// responses are encoded as JSON payloads.
+func HandleStream[Req, Resp any](s *Server, typ string, h requests.ServerStreamHandler[Req, Resp], dec DecodeRequestFunc[Req]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.streams[typ] = func(ctx context.Context, msg Message) (iter.Seq2[any, error], error) {
+		req, err := dec(ctx, msg)
+		if err != nil {
+			return nil, err
+		}
+		return func(yield func(any, error) bool) {
+			for resp, err := range h.HandleStream(ctx, req) {
+				if !yield(resp, err) {
+					return
+				}
+			}
+		}, nil
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := context.WithoutCancel(r.Context())
+	for _, f := range s.before {
+		ctx = f(ctx, r)
+	}
+
+	if !s.checkOrigin(r) {
+		http.Error(w, "websocket: origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	conn, err := Upgrade(w, r)
+	if err != nil {
+		s.errorHandler.Handle(ctx, err)
+		return
+	}
+	conn.MaxMessageSize = s.maxMessageSize
+	if s.pingInterval > 0 {
+		conn.IdleTimeout = s.pingInterval + s.pongTimeout
+	}
+
+	c := &connection{
+		server:  s,
+		conn:    conn,
+		sem:     make(chan struct{}, max(s.maxConcurrency, 1)),
+		streams: make(map[string]*activeStream),
+	}
+	c.serve(ctx)
+}
+
+// connection handles the messages of a single connection.
+type connection struct {
+	server *Server
+	conn   *Conn
+	sem    chan struct{}
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	streams map[string]*activeStream
+}
+
+type activeStream struct {
+	cancel context.CancelFunc
+}
+
+func (c *connection) serve(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		cancel()
+		c.wg.Wait()
+		_ = c.conn.Close()
+	}()
+
+	if interval := c.server.pingInterval; interval > 0 {
+		go c.keepalive(ctx, interval)
+	}
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			var ce *CloseError
+			if !errors.As(err, &ce) || ce.Code != CloseNormalClosure && ce.Code != CloseGoingAway && ce.Code != CloseNoStatusReceived {
+				c.server.errorHandler.Handle(ctx, err)
+			}
+			c.close(err)
+			return
+		}
+
+		var msg Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.server.errorHandler.Handle(ctx, err)
+			c.close(&CloseError{Code: CloseInvalidPayloadData, Reason: "invalid message envelope"})
+			return
+		}
+
+		if msg.Cancel {
+			c.cancelStream(msg.ID)
+			continue
+		}
+
+		select {
+		case c.sem <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		c.wg.Add(1)
+		go func() {
+			defer func() {
+				<-c.sem
+				c.wg.Done()
+			}()
+			defer func() {
+				if r := recover(); r != nil {
+					c.replyError(ctx, msg, fmt.Errorf("websocket: panic handling %q: %v", msg.Type, r))
+				}
+			}()
+			c.handle(ctx, msg)
+		}()
+	}
+}
+
+func (c *connection) keepalive(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := c.conn.WritePing(nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (c *connection) handle(ctx context.Context, msg Message) {
+	c.server.mu.RLock()
+	stream, ok := c.server.streams[msg.Type]
+	c.server.mu.RUnlock()
+
+	if !ok {
+		resp, err := c.server.router.Handle(ctx, msg)
+		if err != nil {
+			c.replyError(ctx, msg, err)
+			return
+		}
+		c.reply(ctx, msg, resp)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if msg.ID != "" {
+		active := &activeStream{cancel: cancel}
+		if !c.startStream(msg.ID, active) {
+			c.replyError(ctx, msg, &StreamIDError{ID: msg.ID})
+			return
+		}
+		defer c.endStream(msg.ID, active)
+	}
+
+	seq, err := stream(ctx, msg)
+	if err != nil {
+		c.replyError(ctx, msg, err)
+		return
+	}
+	for resp, err := range seq {
+		if err != nil {
+			c.replyError(ctx, msg, err)
+			return
+		}
+		if !c.reply(ctx, msg, resp) {
+			return
+		}
+	}
+	c.write(ctx, Message{Type: msg.Type, ID: msg.ID, Done: true})
+}
+
+// startStream registers the stream with the given ID, unless another one is active.
+func (c *connection) startStream(id string, s *activeStream) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.streams[id]; ok {
+		return false
+	}
+	c.streams[id] = s
+	return true
+}
+
+// endStream unregisters the stream, unless it was cancelled and its ID reused.
+func (c *connection) endStream(id string, s *activeStream) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.streams[id] == s {
+		delete(c.streams, id)
+	}
+}
+
+func (c *connection) cancelStream(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if s, ok := c.streams[id]; ok {
+		s.cancel()
+		delete(c.streams, id)
+	}
+}
+
+// reply writes resp as the response to msg and reports whether it succeeded.
+func (c *connection) reply(ctx context.Context, msg Message, resp any) bool {
+	payload, err := json.Marshal(resp)
+	if err != nil {
+		c.replyError(ctx, msg, err)
+		return false
+	}
+	return c.write(ctx, Message{Type: msg.Type, ID: msg.ID, Payload: payload})
+}
+
+func (c *connection) replyError(ctx context.Context, msg Message, err error) {
+	c.server.errorHandler.Handle(ctx, err)
+	c.write(ctx, Message{Type: msg.Type, ID: msg.ID, Error: &ErrorBody{Code: ErrorCode(err), Message: err.Error()}})
+
+	var cc CloseCoder
+	if errors.As(err, &cc) {
+		c.close(err)
+	}
+}
+
+func (c *connection) write(ctx context.Context, msg Message) bool {
+	b, err := json.Marshal(msg)
+	if err == nil {
+		err = c.conn.WriteMessage(TextMessage, b)
+	}
+	if err != nil {
+		if !errors.Is(err, ErrCloseSent) {
+			c.server.errorHandler.Handle(ctx, err)
+		}
+		return false
+	}
+	return true
+}
+
+// close sends the close frame with the code of err.
+func (c *connection) close(err error) {
+	code, reason := CloseInternalError, err.Error()
+	var cc CloseCoder
+	if errors.As(err, &cc) {
+		code = cc.CloseCode()
+	}
+	var ce *CloseError
+	if errors.As(err, &ce) {
+		reason = ce.Reason
+	}
+	_ = c.conn.WriteClose(code, reason)
+}
@@ -0,0 +1,295 @@
+package websocket_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mcosta74/hexkit/adapters/websocket"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+type policyError struct{}
+
+func (policyError) Error() string  { return "forbidden" }
+func (policyError) CloseCode() int { return websocket.ClosePolicyViolation }
+
+func newServer(t *testing.T, options ...websocket.ServerOption) (*websocket.Server, string) {
+	t.Helper()
+
+	s := websocket.NewServer(options...)
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+
+	return s, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func send(t *testing.T, conn *websocket.Conn, msg websocket.Message) {
+	t.Helper()
+
+	b, _ := json.Marshal(msg)
+	if err := conn.WriteMessage(websocket.TextMessage, b); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func receive(t *testing.T, conn *websocket.Conn) websocket.Message {
+	t.Helper()
+
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg websocket.Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestServer(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+
+	s, url := newServer(t, websocket.WithMaxConcurrency(2))
+	websocket.Handle(s, "upper",
+		requests.HandlerFunc[string, string](func(_ context.Context, req string) (string, error) {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				m := maxInFlight.Load()
+				if n <= m || maxInFlight.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return strings.ToUpper(req), nil
+		}),
+		websocket.DecodeJSONRequest[string],
+	)
+	websocket.Handle(s, "forbidden",
+		requests.HandlerFunc[struct{}, struct{}](func(context.Context, struct{}) (struct{}, error) { return struct{}{}, policyError{} }),
+		websocket.DecodeJSONRequest[struct{}],
+	)
+	websocket.Handle(s, "panic",
+		requests.HandlerFunc[struct{}, struct{}](func(context.Context, struct{}) (struct{}, error) { panic("boom") }),
+		websocket.DecodeJSONRequest[struct{}],
+	)
+	websocket.HandleStream(s, "count",
+		requests.ServerStreamHandlerFunc[int, int](func(ctx context.Context, n int) iter.Seq2[int, error] {
+			return func(yield func(int, error) bool) {
+				for i := 1; n == 0 || i <= n; i++ {
+					select {
+					case <-ctx.Done():
+						return
+					default:
+					}
+					if !yield(i, nil) {
+						return
+					}
+					if n == 0 {
+						time.Sleep(5 * time.Millisecond)
+					}
+				}
+			}
+		}),
+		websocket.DecodeJSONRequest[int],
+	)
+
+	t.Run("Correlated Responses", func(t *testing.T) {
+		conn := dial(t, url)
+
+		for _, id := range []string{"1", "2", "3", "4"} {
+			send(t, conn, websocket.Message{Type: "upper", ID: id, Payload: json.RawMessage(`"msg ` + id + `"`)})
+		}
+
+		got := make(map[string]string)
+		for range 4 {
+			msg := receive(t, conn)
+			got[msg.ID] = string(msg.Payload)
+		}
+		for _, id := range []string{"1", "2", "3", "4"} {
+			if want := `"MSG ` + id + `"`; want != got[id] {
+				t.Errorf("unexpected response %s: want=%s, got=%s", id, want, got[id])
+			}
+		}
+		if max := maxInFlight.Load(); max > 2 {
+			t.Errorf("concurrency limit exceeded: %d", max)
+		}
+	})
+
+	t.Run("Unknown Type", func(t *testing.T) {
+		conn := dial(t, url)
+
+		send(t, conn, websocket.Message{Type: "lower", ID: "1"})
+		msg := receive(t, conn)
+		if msg.Error == nil || msg.Error.Code != "400" {
+			t.Errorf("unexpected response: %+v", msg)
+		}
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		conn := dial(t, url)
+
+		send(t, conn, websocket.Message{Type: "panic", ID: "1"})
+		msg := receive(t, conn)
+		if msg.ID != "1" || msg.Error == nil || msg.Error.Code != "500" {
+			t.Errorf("unexpected response: %+v", msg)
+		}
+
+		// the connection is still served
+		send(t, conn, websocket.Message{Type: "upper", ID: "2", Payload: json.RawMessage(`"ok"`)})
+		if msg := receive(t, conn); string(msg.Payload) != `"OK"` {
+			t.Errorf("unexpected response: %+v", msg)
+		}
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		conn := dial(t, url)
+
+		send(t, conn, websocket.Message{Type: "count", ID: "c", Payload: json.RawMessage(`3`)})
+
+		var got []string
+		for {
+			msg := receive(t, conn)
+			if msg.Done {
+				break
+			}
+			got = append(got, string(msg.Payload))
+		}
+		if want := "1,2,3"; want != strings.Join(got, ",") {
+			t.Errorf("unexpected stream: want=%s, got=%s", want, strings.Join(got, ","))
+		}
+	})
+
+	t.Run("Cancel Stream", func(t *testing.T) {
+		conn := dial(t, url)
+
+		send(t, conn, websocket.Message{Type: "count", ID: "c"})
+		receive(t, conn)
+		send(t, conn, websocket.Message{ID: "c", Cancel: true})
+
+		for {
+			msg := receive(t, conn)
+			if msg.Done {
+				break
+			}
+		}
+	})
+
+	t.Run("Duplicate Stream ID", func(t *testing.T) {
+		conn := dial(t, url)
+
+		send(t, conn, websocket.Message{Type: "count", ID: "c"})
+		receive(t, conn)
+		send(t, conn, websocket.Message{Type: "count", ID: "c", Payload: json.RawMessage(`1`)})
+
+		for {
+			msg := receive(t, conn)
+			if msg.Error != nil {
+				if msg.ID != "c" || msg.Error.Code != "409" {
+					t.Errorf("unexpected response: %+v", msg)
+				}
+				break
+			}
+			if msg.Done {
+				t.Fatal("duplicate stream served")
+			}
+		}
+
+		// the first stream is still cancellable
+		send(t, conn, websocket.Message{ID: "c", Cancel: true})
+		for {
+			if msg := receive(t, conn); msg.Done {
+				break
+			}
+		}
+	})
+
+	t.Run("Close Code", func(t *testing.T) {
+		conn := dial(t, url)
+
+		send(t, conn, websocket.Message{Type: "forbidden", ID: "1"})
+		msg := receive(t, conn)
+		if msg.Error == nil || msg.Error.Message != "forbidden" {
+			t.Errorf("unexpected response: %+v", msg)
+		}
+
+		_, _, err := conn.ReadMessage()
+		var ce *websocket.CloseError
+		if !errors.As(err, &ce) || ce.Code != websocket.ClosePolicyViolation {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Invalid Envelope", func(t *testing.T) {
+		conn := dial(t, url)
+
+		if err := conn.WriteMessage(websocket.TextMessage, []byte("not json")); err != nil {
+			t.Fatal(err)
+		}
+
+		_, _, err := conn.ReadMessage()
+		var ce *websocket.CloseError
+		if !errors.As(err, &ce) || ce.Code != websocket.CloseInvalidPayloadData {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestServerOrigin(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		options []websocket.ServerOption
+		origin  func(url string) string
+		status  int
+	}{
+		{"Same Origin", nil, func(url string) string { return "http" + strings.TrimPrefix(url, "ws") }, http.StatusSwitchingProtocols},
+		{"No Origin", nil, func(string) string { return "" }, http.StatusSwitchingProtocols},
+		{"Cross Origin", nil, func(string) string { return "https://evil.example" }, http.StatusForbidden},
+		{"Any Origin", []websocket.ServerOption{websocket.WithCheckOrigin(websocket.AnyOrigin)}, func(string) string { return "https://evil.example" }, http.StatusSwitchingProtocols},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, url := newServer(t, tc.options...)
+
+			header := http.Header{}
+			if origin := tc.origin(url); origin != "" {
+				header.Set("Origin", origin)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer cancel()
+
+			conn, err := websocket.Dial(ctx, url, header)
+			if tc.status == http.StatusSwitchingProtocols {
+				if err != nil {
+					t.Fatal(err)
+				}
+				conn.Close()
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "403") {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestServerKeepalive(t *testing.T) {
+	_, url := newServer(t, websocket.WithKeepalive(20*time.Millisecond, 20*time.Millisecond))
+	conn := dial(t, url)
+
+	// the client doesn't read, so it doesn't answer the pings: the server gives up
+	time.Sleep(200 * time.Millisecond)
+
+	_, _, err := conn.ReadMessage()
+	var ce *websocket.CloseError
+	if !errors.As(err, &ce) || ce.Code != websocket.CloseInternalError {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
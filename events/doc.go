@@ -0,0 +1,3 @@
+// Package events provides a port to publish domain events and a transactional outbox
+// which publishes the events staged by a request handler only once it succeeds.
+package events
@@ -0,0 +1,57 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// Event is a domain event ready to be published.
+type Event struct {
+	// ID identifies the event; brokers use it to drop duplicates.
+	ID string
+	// Subject is where the event is published.
+	Subject string
+	// Header carries optional metadata.
+	Header map[string]string
+	// Data is the encoded payload.
+	Data []byte
+	// Time is when the event was created.
+	Time time.Time
+}
+
+// New creates an event with a random ID, whose payload is data encoded as JSON.
+func New[T any](subject string, data T) (Event, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{
+		ID:      NewID(),
+		Subject: subject,
+		Data:    b,
+		Time:    time.Now(),
+	}, nil
+}
+
+// NewID returns a random event ID.
+func NewID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// Publisher publishes events to a broker.
+type Publisher interface {
+	Publish(ctx context.Context, events ...Event) error
+}
+
+// PublisherFunc is an adapter to allow the use of ordinary functions as [Publisher].
+type PublisherFunc func(ctx context.Context, events ...Event) error
+
+// Publish implements [Publisher].
+func (f PublisherFunc) Publish(ctx context.Context, events ...Event) error {
+	return f(ctx, events...)
+}
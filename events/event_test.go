@@ -0,0 +1,24 @@
+package events_test
+
+import (
+	"testing"
+
+	"github.com/mcosta74/hexkit/events"
+)
+
+func TestNew(t *testing.T) {
+	e, err := events.New("orders.created", map[string]int{"id": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := `{"id":1}`, string(e.Data); want != got {
+		t.Errorf("unexpected data: want=%s, got=%s", want, got)
+	}
+	if e.ID == "" || e.Time.IsZero() || e.Subject != "orders.created" {
+		t.Errorf("unexpected event: %+v", e)
+	}
+
+	if _, err := events.New("orders.created", func() {}); err == nil {
+		t.Error("expected encoding error")
+	}
+}
@@ -0,0 +1,28 @@
+package events
+
+import (
+	"context"
+
+	"github.com/mcosta74/hexkit/requests"
+)
+
+// Handler consumes events of type E.
+type Handler[E any] interface {
+	HandleEvent(ctx context.Context, event E) error
+}
+
+// HandlerFunc is an adapter to allow the use of ordinary functions as [Handler].
+type HandlerFunc[E any] func(ctx context.Context, event E) error
+
+// HandleEvent implements [Handler].
+func (f HandlerFunc[E]) HandleEvent(ctx context.Context, event E) error {
+	return f(ctx, event)
+}
+
+// FromRequestHandler adapts a request handler to a [Handler] discarding its responses.
+func FromRequestHandler[E, Resp any](h requests.Handler[E, Resp]) Handler[E] {
+	return HandlerFunc[E](func(ctx context.Context, event E) error {
+		_, err := h.Handle(ctx, event)
+		return err
+	})
+}
@@ -0,0 +1,28 @@
+package events_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mcosta74/hexkit/events"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+func TestFromRequestHandler(t *testing.T) {
+	var got string
+	h := events.FromRequestHandler(requests.HandlerFunc[string, int](func(_ context.Context, req string) (int, error) {
+		got = req
+		if req == "fail" {
+			return 0, errors.New("fail")
+		}
+		return len(req), nil
+	}))
+
+	if err := h.HandleEvent(context.Background(), "created"); err != nil || got != "created" {
+		t.Errorf("unexpected result: got=%q, err=%v", got, err)
+	}
+	if err := h.HandleEvent(context.Background(), "fail"); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -0,0 +1,113 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/mcosta74/hexkit/adapters"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+// ErrNoOutbox is returned by [Stage] when the context doesn't come from the outbox middleware.
+var ErrNoOutbox = errors.New("events: no outbox in context")
+
+type contextKey int
+
+const outboxContextKey contextKey = iota
+
+// staged collects the events saved during a request.
+type staged struct {
+	store OutboxStore
+
+	mu     sync.Mutex
+	events []Event
+}
+
+// Stage saves events in the outbox of the request handled with ctx (see [NewOutbox]).
+// The events are published after the handler succeeds.
+func Stage(ctx context.Context, events ...Event) error {
+	s, ok := ctx.Value(outboxContextKey).(*staged)
+	if !ok {
+		return ErrNoOutbox
+	}
+
+	if err := s.store.Save(ctx, events...); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, events...)
+	return nil
+}
+
+// OutboxOption sets optional parameters for the outbox middleware.
+type OutboxOption func(c *outboxConfig)
+
+type outboxConfig struct {
+	errorHandler adapters.ErrorHandler
+}
+
+// WithOutboxErrorHandler sets the handler for the errors publishing or discarding the staged events.
+// These errors never fail the request: events which couldn't be published are left
+// in the store for the [Relay].
+func WithOutboxErrorHandler(eh adapters.ErrorHandler) OutboxOption {
+	return func(c *outboxConfig) {
+		c.errorHandler = eh
+	}
+}
+
+// NewOutbox returns a [requests.Middleware] letting the wrapped handler [Stage] events in store.
+//
+// When the handler succeeds the staged events are published with pub and deleted
+// from the store; when it fails they are deleted without being published.
+func NewOutbox[Req, Resp any](store OutboxStore, pub Publisher, options ...OutboxOption) requests.Middleware[Req, Resp] {
+	cfg := outboxConfig{
+		errorHandler: adapters.NewNoOpErrorHandler(),
+	}
+	for _, o := range options {
+		o(&cfg)
+	}
+
+	return func(next requests.Handler[Req, Resp]) requests.Handler[Req, Resp] {
+		return requests.HandlerFunc[Req, Resp](func(ctx context.Context, req Req) (Resp, error) {
+			s := &staged{store: store}
+
+			resp, err := next.Handle(context.WithValue(ctx, outboxContextKey, s), req)
+
+			s.mu.Lock()
+			events := s.events
+			s.events = nil
+			s.mu.Unlock()
+			if len(events) == 0 {
+				return resp, err
+			}
+
+			ctx = context.WithoutCancel(ctx)
+			if err != nil {
+				if derr := store.Delete(ctx, ids(events)...); derr != nil {
+					cfg.errorHandler.Handle(ctx, derr)
+				}
+				return resp, err
+			}
+
+			if perr := pub.Publish(ctx, events...); perr != nil {
+				cfg.errorHandler.Handle(ctx, perr)
+				return resp, nil
+			}
+			if derr := store.Delete(ctx, ids(events)...); derr != nil {
+				cfg.errorHandler.Handle(ctx, derr)
+			}
+			return resp, nil
+		})
+	}
+}
+
+func ids(events []Event) []string {
+	ids := make([]string, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+	return ids
+}
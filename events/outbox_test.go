@@ -0,0 +1,98 @@
+package events_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mcosta74/hexkit/adapters"
+	"github.com/mcosta74/hexkit/events"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+type recorder struct {
+	published []events.Event
+	err       error
+}
+
+func (r *recorder) Publish(_ context.Context, evs ...events.Event) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.published = append(r.published, evs...)
+	return nil
+}
+
+func pending(t *testing.T, store events.OutboxStore) []events.Event {
+	t.Helper()
+
+	evs, err := store.Pending(context.Background(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return evs
+}
+
+func TestOutbox(t *testing.T) {
+	handler := requests.HandlerFunc[string, string](func(ctx context.Context, req string) (string, error) {
+		e, _ := events.New("greeted", req)
+		if err := events.Stage(ctx, e); err != nil {
+			return "", err
+		}
+		if req == "fail" {
+			return "", errors.New("fail")
+		}
+		return "hello " + req, nil
+	})
+
+	t.Run("Published On Success", func(t *testing.T) {
+		store, pub := events.NewMemoryStore(), &recorder{}
+		h := events.NewOutbox[string, string](store, pub)(handler)
+
+		if _, err := h.Handle(context.Background(), "bob"); err != nil {
+			t.Fatal(err)
+		}
+		if len(pub.published) != 1 || string(pub.published[0].Data) != `"bob"` {
+			t.Errorf("unexpected published events: %+v", pub.published)
+		}
+		if evs := pending(t, store); len(evs) != 0 {
+			t.Errorf("unexpected pending events: %+v", evs)
+		}
+	})
+
+	t.Run("Discarded On Failure", func(t *testing.T) {
+		store, pub := events.NewMemoryStore(), &recorder{}
+		h := events.NewOutbox[string, string](store, pub)(handler)
+
+		if _, err := h.Handle(context.Background(), "fail"); err == nil {
+			t.Fatal("expected error")
+		}
+		if len(pub.published) != 0 || len(pending(t, store)) != 0 {
+			t.Errorf("unexpected events: published=%+v", pub.published)
+		}
+	})
+
+	t.Run("Kept When Publishing Fails", func(t *testing.T) {
+		store, pub := events.NewMemoryStore(), &recorder{err: errors.New("broker down")}
+		var reported error
+		h := events.NewOutbox[string, string](store, pub,
+			events.WithOutboxErrorHandler(adapters.ErrorHandlerFunc(func(_ context.Context, err error) { reported = err })),
+		)(handler)
+
+		if _, err := h.Handle(context.Background(), "bob"); err != nil {
+			t.Fatal(err)
+		}
+		if reported == nil {
+			t.Error("error not reported")
+		}
+		if evs := pending(t, store); len(evs) != 1 {
+			t.Errorf("unexpected pending events: %+v", evs)
+		}
+	})
+
+	t.Run("No Outbox", func(t *testing.T) {
+		if _, err := handler.Handle(context.Background(), "bob"); !errors.Is(err, events.ErrNoOutbox) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
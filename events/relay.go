@@ -0,0 +1,124 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/mcosta74/hexkit/adapters"
+)
+
+// Relay publishes the events left in an outbox store, e.g. because the broker was
+// unavailable when the request completed or the process crashed meanwhile.
+//
+// Events may be published more than once: consumers should use the event ID
+// to drop duplicates.
+type Relay struct {
+	store        OutboxStore
+	pub          Publisher
+	interval     time.Duration
+	batchSize    int
+	minAge       time.Duration
+	errorHandler adapters.ErrorHandler
+}
+
+// RelayOption sets optional parameters for the relay.
+type RelayOption func(r *Relay)
+
+// WithRelayInterval sets the interval between the scans of the store (default 1s).
+func WithRelayInterval(d time.Duration) RelayOption {
+	return func(r *Relay) {
+		r.interval = d
+	}
+}
+
+// WithRelayBatchSize sets the maximum number of events published by each scan (default 100).
+func WithRelayBatchSize(n int) RelayOption {
+	return func(r *Relay) {
+		r.batchSize = n
+	}
+}
+
+// WithRelayMinAge makes the relay skip events younger than d, which are likely still
+// being published by the outbox middleware (default 5s).
+func WithRelayMinAge(d time.Duration) RelayOption {
+	return func(r *Relay) {
+		r.minAge = d
+	}
+}
+
+// WithRelayErrorHandler sets the error handler for the relay.
+func WithRelayErrorHandler(eh adapters.ErrorHandler) RelayOption {
+	return func(r *Relay) {
+		r.errorHandler = eh
+	}
+}
+
+// WithRelayErrorLogger sets a error handler for the relay that logs errors.
+func WithRelayErrorLogger(logger *slog.Logger) RelayOption {
+	return func(r *Relay) {
+		r.errorHandler = adapters.NewSlogErrorHandler(logger)
+	}
+}
+
+// NewRelay creates a relay publishing with pub the events pending in store.
+func NewRelay(store OutboxStore, pub Publisher, options ...RelayOption) *Relay {
+	r := &Relay{
+		store:        store,
+		pub:          pub,
+		interval:     time.Second,
+		batchSize:    100,
+		minAge:       5 * time.Second,
+		errorHandler: adapters.NewNoOpErrorHandler(),
+	}
+
+	for _, o := range options {
+		o(r)
+	}
+	return r
+}
+
+// Run scans the store periodically until ctx is done. Errors are reported to the
+// error handler and the events retried at the next scan.
+func (r *Relay) Run(ctx context.Context) error {
+	t := time.NewTicker(r.interval)
+	defer t.Stop()
+
+	for {
+		if _, err := r.Flush(ctx); err != nil && ctx.Err() == nil {
+			r.errorHandler.Handle(ctx, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+// Flush publishes a batch of pending events and returns how many were published.
+// Events are published one at a time, so an error stops the batch without losing
+// the events already published.
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	events, err := r.store.Pending(ctx, r.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	threshold := time.Now().Add(-r.minAge)
+	for _, e := range events {
+		if e.Time.After(threshold) {
+			continue
+		}
+		if err := r.pub.Publish(ctx, e); err != nil {
+			return published, err
+		}
+		if err := r.store.Delete(ctx, e.ID); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
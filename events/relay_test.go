@@ -0,0 +1,47 @@
+package events_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mcosta74/hexkit/events"
+)
+
+func TestRelay(t *testing.T) {
+	ctx := context.Background()
+
+	store := events.NewMemoryStore()
+	old, _ := events.New("orders.created", 1)
+	old.Time = time.Now().Add(-time.Minute)
+	recent, _ := events.New("orders.created", 2)
+	if err := store.Save(ctx, old, recent); err != nil {
+		t.Fatal(err)
+	}
+
+	pub := &recorder{err: errors.New("broker down")}
+	relay := events.NewRelay(store, pub, events.WithRelayMinAge(time.Second))
+
+	if n, err := relay.Flush(ctx); n != 0 || err == nil {
+		t.Errorf("unexpected flush result: n=%d, err=%v", n, err)
+	}
+
+	pub.err = nil
+	if n, err := relay.Flush(ctx); n != 1 || err != nil {
+		t.Errorf("unexpected flush result: n=%d, err=%v", n, err)
+	}
+	if len(pub.published) != 1 || pub.published[0].ID != old.ID {
+		t.Errorf("unexpected published events: %+v", pub.published)
+	}
+
+	relay = events.NewRelay(store, pub, events.WithRelayMinAge(0), events.WithRelayInterval(10*time.Millisecond))
+	ctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	if err := relay.Run(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if evs := pending(t, store); len(evs) != 0 || len(pub.published) != 2 {
+		t.Errorf("unexpected state: pending=%+v, published=%+v", evs, pub.published)
+	}
+}
@@ -0,0 +1,64 @@
+package events
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// OutboxStore is the storage of the events waiting to be published.
+//
+// Stores backed by a database should save the events in the transaction of the
+// request handler, e.g. one carried by the context, so that the events are
+// committed if and only if the changes of the handler are.
+type OutboxStore interface {
+	// Save stores the events.
+	Save(ctx context.Context, events ...Event) error
+	// Pending returns up to limit events not published yet, oldest first.
+	Pending(ctx context.Context, limit int) ([]Event, error)
+	// Delete removes the events with the given IDs, once published or discarded.
+	Delete(ctx context.Context, ids ...string) error
+}
+
+// MemoryStore is an in-memory [OutboxStore].
+type MemoryStore struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+// Save implements [OutboxStore].
+func (s *MemoryStore) Save(_ context.Context, events ...Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, events...)
+	return nil
+}
+
+// Pending implements [OutboxStore].
+func (s *MemoryStore) Pending(_ context.Context, limit int) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := len(s.events)
+	if limit > 0 {
+		n = min(n, limit)
+	}
+	return slices.Clone(s.events[:n]), nil
+}
+
+// Delete implements [OutboxStore].
+func (s *MemoryStore) Delete(_ context.Context, ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = slices.DeleteFunc(s.events, func(e Event) bool {
+		return slices.Contains(ids, e.ID)
+	})
+	return nil
+}
@@ -0,0 +1,411 @@
+// Package jsonschema derives JSON Schemas (draft 2020-12) from Go types by reflection.
+//
+// Field names and optionality follow the `json` tags; the `validate` tags
+// (required, min, max, len, oneof, email, url, uri, uuid) add constraints and the
+// `doc` tags add descriptions.
+package jsonschema
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+
+	// Nullable makes the schema accept null too, as for pointers, slices and maps.
+	Nullable bool `json:"-"`
+}
+
+// MarshalJSON implements json.Marshaler, adding "null" to the types of the nullable schemas.
+func (s Schema) MarshalJSON() ([]byte, error) {
+	type schema Schema
+	switch {
+	case !s.Nullable:
+		return json.Marshal(schema(s))
+	case s.Ref != "":
+		return json.Marshal(struct {
+			AnyOf       []*Schema `json:"anyOf"`
+			Description string    `json:"description,omitempty"`
+		}{
+			AnyOf:       []*Schema{{Ref: s.Ref}, {Type: "null"}},
+			Description: s.Description,
+		})
+	case s.Type == "":
+		// accepts any value, null included
+		return json.Marshal(schema(s))
+	}
+	return json.Marshal(struct {
+		Type []string `json:"type"`
+		schema
+	}{
+		Type:   []string{s.Type, "null"},
+		schema: schema(s),
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler, reading the nullable schemas written by MarshalJSON.
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	type schema Schema
+	var aux struct {
+		Type  json.RawMessage `json:"type"`
+		AnyOf []*Schema       `json:"anyOf"`
+		*schema
+	}
+	aux.schema = (*schema)(s)
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if len(aux.AnyOf) == 2 && aux.AnyOf[0].Ref != "" && aux.AnyOf[1].Type == "null" {
+		s.Ref, s.Nullable = aux.AnyOf[0].Ref, true
+	}
+	if len(aux.Type) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(aux.Type, &s.Type); err == nil {
+		return nil
+	}
+	var types []string
+	if err := json.Unmarshal(aux.Type, &types); err != nil {
+		return err
+	}
+	for _, t := range types {
+		if t == "null" {
+			s.Nullable = true
+		} else {
+			s.Type = t
+		}
+	}
+	return nil
+}
+
+// Generator generates schemas, collecting the named struct types as definitions
+// referenced by their name.
+type Generator struct {
+	// RefPrefix is prepended to the names of the definitions to build the references,
+	// e.g. "#/$defs/" or "#/components/schemas/".
+	RefPrefix string
+	// SkipField, if set, excludes the struct fields for which it returns true.
+	SkipField func(reflect.StructField) bool
+
+	defs  map[string]*Schema
+	names map[reflect.Type]string
+}
+
+// NewGenerator creates a generator which references definitions with refPrefix.
+func NewGenerator(refPrefix string) *Generator {
+	return &Generator{
+		RefPrefix: refPrefix,
+		defs:      make(map[string]*Schema),
+		names:     make(map[reflect.Type]string),
+	}
+}
+
+// Definitions returns the definitions collected so far, by name.
+func (g *Generator) Definitions() map[string]*Schema {
+	return g.defs
+}
+
+// For returns the schema of the Go type T, a struct in standalone form with
+// the definitions inlined in "$defs".
+func For[T any]() *Standalone {
+	g := NewGenerator("#/$defs/")
+	s := g.Schema(reflect.TypeFor[T]())
+	return &Standalone{Schema: *s, Defs: g.defs}
+}
+
+// Standalone is a root schema carrying its definitions.
+type Standalone struct {
+	Schema
+	Defs map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler adding the dialect of the schema.
+func (s *Standalone) MarshalJSON() ([]byte, error) {
+	schema, err := json.Marshal(s.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	b := []byte(`{"$schema":"https://json-schema.org/draft/2020-12/schema"`)
+	if len(schema) > 2 {
+		b = append(append(b, ','), schema[1:len(schema)-1]...)
+	}
+	if len(s.Defs) > 0 {
+		defs, err := json.Marshal(s.Defs)
+		if err != nil {
+			return nil, err
+		}
+		b = append(append(b, `,"$defs":`...), defs...)
+	}
+	return append(b, '}'), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, which would be otherwise promoted from Schema.
+func (s *Standalone) UnmarshalJSON(data []byte) error {
+	var defs struct {
+		Defs map[string]*Schema `json:"$defs"`
+	}
+	if err := json.Unmarshal(data, &defs); err != nil {
+		return err
+	}
+	s.Defs = defs.Defs
+	return json.Unmarshal(data, &s.Schema)
+}
+
+// Resolve returns the schema s refers to, if s is a reference to a collected definition.
+func (g *Generator) Resolve(s *Schema) *Schema {
+	if name, ok := strings.CutPrefix(s.Ref, g.RefPrefix); ok && s.Ref != "" {
+		if def, ok := g.defs[name]; ok {
+			return def
+		}
+	}
+	return s
+}
+
+var (
+	timeType            = reflect.TypeFor[time.Time]()
+	durationType        = reflect.TypeFor[time.Duration]()
+	rawMessageType      = reflect.TypeFor[json.RawMessage]()
+	jsonMarshalerType   = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType   = reflect.TypeFor[encoding.TextMarshaler]()
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+)
+
+// Schema returns the schema of t. Named struct types are returned as references
+// to definitions.
+func (g *Generator) Schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == durationType:
+		return &Schema{Type: "integer", Format: "int64", Description: "duration in nanoseconds"}
+	case t == rawMessageType:
+		return &Schema{}
+	case implements(t, jsonMarshalerType):
+		return &Schema{}
+	case implements(t, textMarshalerType) || implements(t, textUnmarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32", Minimum: ptr(0.0)}
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: "integer", Format: "int64", Minimum: ptr(0.0)}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 && t.Kind() == reflect.Slice {
+			return &Schema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &Schema{Type: "array", Items: g.Schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.Schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return g.ref(t)
+	}
+	return &Schema{}
+}
+
+func (g *Generator) ref(t reflect.Type) *Schema {
+	if name, ok := g.names[t]; ok {
+		return &Schema{Ref: g.RefPrefix + name}
+	}
+
+	base := t.Name()
+	if i := strings.IndexByte(base, '['); i >= 0 {
+		base = base[:i]
+	}
+	name := base
+	for i := 2; g.defs[name] != nil; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	g.names[t] = name
+	g.defs[name] = &Schema{} // placeholder for recursive types
+	*g.defs[name] = *g.structSchema(t)
+
+	return &Schema{Ref: g.RefPrefix + name}
+}
+
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for _, sf := range reflect.VisibleFields(t) {
+		if !sf.IsExported() || (sf.Anonymous && sf.Type.Kind() == reflect.Struct) {
+			continue
+		}
+		if g.SkipField != nil && g.SkipField(sf) {
+			continue
+		}
+
+		name, opts := sf.Name, ""
+		if tag, ok := sf.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+			var n string
+			n, opts, _ = strings.Cut(tag, ",")
+			if n != "" {
+				name = n
+			}
+		}
+
+		fs := g.Schema(sf.Type)
+		if strings.Contains(","+opts+",", ",string,") {
+			fs = &Schema{Type: "string", Format: fs.Format}
+		}
+		if k := sf.Type.Kind(); k == reflect.Pointer || k == reflect.Slice || k == reflect.Map {
+			// encoding/json decodes null into the nil value
+			fs = nullable(fs)
+		}
+		if doc := sf.Tag.Get("doc"); doc != "" {
+			fs = withDescription(fs, doc)
+		}
+		if ApplyValidateTag(fs, g.Resolve(fs), sf.Tag.Get("validate")) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = fs
+	}
+	return s
+}
+
+// ApplyValidateTag applies the constraints of the validate tag to s, using resolved to
+// know the type of s when it's a reference. It reports whether the tag contains "required".
+func ApplyValidateTag(s, resolved *Schema, tag string) (required bool) {
+	if tag == "" {
+		return false
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "email":
+			s.Format = "email"
+		case "url", "uri":
+			s.Format = "uri"
+		case "uuid":
+			s.Format = "uuid"
+		case "oneof":
+			for _, v := range strings.Fields(value) {
+				s.Enum = append(s.Enum, enumValue(resolved.Type, v))
+			}
+		case "min", "gte", "max", "lte", "len":
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			applyBound(s, resolved.Type, key, n)
+		}
+	}
+	return required
+}
+
+func applyBound(s *Schema, typ, key string, n float64) {
+	lower := key == "min" || key == "gte" || key == "len"
+	upper := key == "max" || key == "lte" || key == "len"
+
+	switch typ {
+	case "string":
+		if lower {
+			s.MinLength = ptr(int(n))
+		}
+		if upper {
+			s.MaxLength = ptr(int(n))
+		}
+	case "array":
+		if lower {
+			s.MinItems = ptr(int(n))
+		}
+		if upper {
+			s.MaxItems = ptr(int(n))
+		}
+	case "integer", "number":
+		if lower {
+			s.Minimum = ptr(n)
+		}
+		if upper {
+			s.Maximum = ptr(n)
+		}
+	}
+}
+
+func enumValue(typ, v string) any {
+	switch typ {
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return v
+}
+
+// withDescription returns s with the description set, without altering shared definitions.
+func withDescription(s *Schema, doc string) *Schema {
+	if s.Ref != "" {
+		return &Schema{Ref: s.Ref, Description: doc, Nullable: s.Nullable}
+	}
+	s.Description = doc
+	return s
+}
+
+// nullable returns s accepting null, without altering shared definitions.
+func nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		return &Schema{Ref: s.Ref, Description: s.Description, Nullable: true}
+	}
+	s.Nullable = true
+	return s
+}
+
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,111 @@
+package jsonschema_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mcosta74/hexkit/internal/jsonschema"
+)
+
+type Page[T any] struct {
+	Items []T `json:"items"`
+	Next  *Page[T]
+}
+
+type Order struct {
+	ID       string            `json:"id" validate:"required,uuid" doc:"order identifier"`
+	Email    string            `json:"email,omitempty" validate:"email"`
+	Quantity int               `json:"quantity" validate:"min=1,max=10"`
+	Status   string            `json:"status" validate:"oneof=open closed"`
+	Created  time.Time         `json:"created"`
+	Labels   map[string]string `json:"labels"`
+	Data     []byte            `json:"data"`
+	Amount   int64             `json:"amount,string"`
+	Secret   string            `json:"-"`
+	internal string
+}
+
+func TestFor(t *testing.T) {
+	got, err := json.Marshal(jsonschema.For[Order]())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"$schema":"https://json-schema.org/draft/2020-12/schema","$ref":"#/$defs/Order","$defs":{"Order":{` +
+		`"type":"object","properties":{` +
+		`"amount":{"type":"string","format":"int64"},` +
+		`"created":{"type":"string","format":"date-time"},` +
+		`"data":{"type":["string","null"],"contentEncoding":"base64"},` +
+		`"email":{"type":"string","format":"email"},` +
+		`"id":{"type":"string","format":"uuid","description":"order identifier"},` +
+		`"labels":{"type":["object","null"],"additionalProperties":{"type":"string"}},` +
+		`"quantity":{"type":"integer","format":"int64","minimum":1,"maximum":10},` +
+		`"status":{"type":"string","enum":["open","closed"]}},` +
+		`"required":["id"]}}}`
+	if want != string(got) {
+		t.Errorf("unexpected schema:\nwant=%s\ngot= %s", want, got)
+	}
+}
+
+func TestNullable(t *testing.T) {
+	b, err := json.Marshal(jsonschema.For[Page[string]]())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got jsonschema.Standalone
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	page := got.Defs["Page"]
+	if next := page.Properties["Next"]; next.Ref != "#/$defs/Page" || !next.Nullable {
+		t.Errorf("unexpected pointer schema: %+v", next)
+	}
+	if items := page.Properties["items"]; items.Type != "array" || !items.Nullable {
+		t.Errorf("unexpected slice schema: %+v", items)
+	}
+}
+
+func TestGenerator(t *testing.T) {
+	t.Run("Recursive Generic", func(t *testing.T) {
+		g := jsonschema.NewGenerator("#/components/schemas/")
+
+		s := g.Schema(reflect.TypeFor[Page[Order]]())
+		if want, got := "#/components/schemas/Page", s.Ref; want != got {
+			t.Fatalf("unexpected ref: want=%q, got=%q", want, got)
+		}
+
+		page := g.Resolve(s)
+		if want, got := "#/components/schemas/Page", page.Properties["Next"].Ref; want != got {
+			t.Errorf("unexpected recursive ref: want=%q, got=%q", want, got)
+		}
+		if want, got := "#/components/schemas/Order", page.Properties["items"].Items.Ref; want != got {
+			t.Errorf("unexpected items ref: want=%q, got=%q", want, got)
+		}
+	})
+
+	t.Run("Name Collision", func(t *testing.T) {
+		g := jsonschema.NewGenerator("#/$defs/")
+
+		a := g.Schema(reflect.TypeFor[Page[string]]())
+		b := g.Schema(reflect.TypeFor[Page[int]]())
+		if a.Ref == b.Ref {
+			t.Errorf("unexpected shared ref %q", a.Ref)
+		}
+		if want, got := 2, len(g.Definitions()); want != got {
+			t.Errorf("unexpected number of definitions: want=%d, got=%d", want, got)
+		}
+	})
+
+	t.Run("Skip Field", func(t *testing.T) {
+		g := jsonschema.NewGenerator("#/$defs/")
+		g.SkipField = func(sf reflect.StructField) bool { return sf.Name == "Labels" }
+
+		s := g.Resolve(g.Schema(reflect.TypeFor[Order]()))
+		if _, ok := s.Properties["labels"]; ok {
+			t.Error("unexpected skipped property")
+		}
+	})
+}
@@ -0,0 +1,195 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Validate validates the JSON document data against s and returns the violations found,
+// each one prefixed by the JSON pointer of the offending value. An empty document is
+// valid for an object without required properties, as it decodes to the zero value.
+func (s *Standalone) Validate(data []byte) []string {
+	var v any
+	if len(bytes.TrimSpace(data)) == 0 {
+		if root := s.root(); root.Type == "object" && len(root.Required) == 0 {
+			return nil
+		}
+	} else {
+		if err := json.Unmarshal(data, &v); err != nil {
+			return []string{"invalid JSON: " + err.Error()}
+		}
+	}
+
+	vd := validator{defs: s.Defs}
+	vd.validate(&s.Schema, v, "")
+	return vd.violations
+}
+
+// root returns the root schema, resolving its reference.
+func (s *Standalone) root() *Schema {
+	if def, ok := s.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")]; ok && s.Ref != "" {
+		return def
+	}
+	return &s.Schema
+}
+
+type validator struct {
+	defs       map[string]*Schema
+	violations []string
+}
+
+func (vd *validator) addf(path, format string, args ...any) {
+	if path == "" {
+		path = "/"
+	}
+	vd.violations = append(vd.violations, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (vd *validator) validate(s *Schema, v any, path string) {
+	if v == nil && s.Nullable {
+		return
+	}
+	if s.Ref != "" {
+		def, ok := vd.defs[strings.TrimPrefix(s.Ref, "#/$defs/")]
+		if !ok {
+			vd.addf(path, "unresolvable reference %q", s.Ref)
+			return
+		}
+		s = def
+	}
+
+	if s.Type != "" && !hasType(v, s.Type) {
+		vd.addf(path, "expected %s, got %s", s.Type, typeOf(v))
+		return
+	}
+
+	if len(s.Enum) > 0 && !slices.ContainsFunc(s.Enum, func(e any) bool { return equal(e, v) }) {
+		vd.addf(path, "value must be one of %v", s.Enum)
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				vd.addf(path, "missing required property %q", name)
+			}
+		}
+		for name, pv := range v {
+			ps, ok := s.Properties[name]
+			if !ok {
+				ps = s.AdditionalProperties
+			}
+			if ps != nil {
+				vd.validate(ps, pv, path+"/"+escapePointer(name))
+			}
+		}
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			vd.addf(path, "must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			vd.addf(path, "must have at most %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, iv := range v {
+				vd.validate(s.Items, iv, fmt.Sprintf("%s/%d", path, i))
+			}
+		}
+	case string:
+		n := utf8.RuneCountInString(v)
+		if s.MinLength != nil && n < *s.MinLength {
+			vd.addf(path, "length must be at least %d", *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			vd.addf(path, "length must be at most %d", *s.MaxLength)
+		}
+		if s.Format != "" && !validFormat(s.Format, v) {
+			vd.addf(path, "invalid %s", s.Format)
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			vd.addf(path, "must be greater than or equal to %v", *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			vd.addf(path, "must be less than or equal to %v", *s.Maximum)
+		}
+	}
+}
+
+func hasType(v any, typ string) bool {
+	switch typ {
+	case "integer":
+		n, ok := v.(float64)
+		return ok && n == math.Trunc(n)
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	}
+	return typeOf(v) == typ
+}
+
+func typeOf(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "unknown"
+}
+
+// equal compares an enum value with a decoded JSON value.
+func equal(e, v any) bool {
+	if n, ok := v.(float64); ok {
+		switch e := e.(type) {
+		case float64:
+			return e == n
+		case int:
+			return float64(e) == n
+		}
+	}
+	return reflect.DeepEqual(e, v)
+}
+
+var uuidRe = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func validFormat(format, v string) bool {
+	switch format {
+	case "email":
+		_, err := mail.ParseAddress(v)
+		return err == nil
+	case "uri":
+		u, err := url.Parse(v)
+		return err == nil && u.Scheme != ""
+	case "uuid":
+		return uuidRe.MatchString(v)
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, v)
+		return err == nil
+	}
+	return true
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func escapePointer(s string) string {
+	return pointerEscaper.Replace(s)
+}
@@ -0,0 +1,130 @@
+package jsonschema_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/mcosta74/hexkit/internal/jsonschema"
+)
+
+type LineItem struct {
+	SKU      string `json:"sku" validate:"required,len=4"`
+	Quantity int    `json:"quantity" validate:"min=1"`
+}
+
+type PlaceOrder struct {
+	ID     string            `json:"id" validate:"required,uuid"`
+	Email  string            `json:"email" validate:"email"`
+	Status string            `json:"status" validate:"oneof=open closed"`
+	Items  []LineItem        `json:"items" validate:"min=1"`
+	Labels map[string]string `json:"labels"`
+}
+
+func TestValidate(t *testing.T) {
+	schema := jsonschema.For[PlaceOrder]()
+
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "Valid",
+			data: `{"id":"0b6a3f0e-7c1e-4e0b-9d2f-3c9b1f5a2e11","email":"a@b.c","status":"open","items":[{"sku":"AB12","quantity":2}],"labels":{"k":"v"}}`,
+		},
+		{
+			name: "Invalid JSON",
+			data: `{`,
+			want: []string{"invalid JSON: unexpected end of JSON input"},
+		},
+		{
+			name: "Wrong Type",
+			data: `[]`,
+			want: []string{"/: expected object, got array"},
+		},
+		{
+			name: "Violations",
+			data: `{"id":"x","email":"nope","status":"lost","items":[{"sku":"A","quantity":1.5}],"labels":{"k":1}}`,
+			want: []string{
+				"/email: invalid email",
+				"/id: invalid uuid",
+				"/items/0/quantity: expected integer, got number",
+				"/items/0/sku: length must be at least 4",
+				"/labels/k: expected string, got number",
+				`/status: value must be one of [open closed]`,
+			},
+		},
+		{
+			name: "Missing Required",
+			data: `{"items":[]}`,
+			want: []string{
+				`/: missing required property "id"`,
+				"/items: must have at least 1 items",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := schema.Validate([]byte(tt.data))
+			slices.Sort(got)
+			if !slices.Equal(tt.want, got) {
+				t.Errorf("unexpected violations:\nwant=%q\ngot= %q", tt.want, got)
+			}
+		})
+	}
+}
+
+type UpdateOrder struct {
+	Name   *string           `json:"name"`
+	Tags   []string          `json:"tags"`
+	Labels map[string]string `json:"labels"`
+	Next   *LineItem         `json:"next"`
+}
+
+func TestValidateNull(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema *jsonschema.Standalone
+		data   string
+		want   []string
+	}{
+		{
+			name:   "Null Fields",
+			schema: jsonschema.For[UpdateOrder](),
+			data:   `{"name":null,"tags":null,"labels":null,"next":null}`,
+		},
+		{
+			name:   "Null Value",
+			schema: jsonschema.For[PlaceOrder](),
+			data:   `{"id":null,"items":null}`,
+			want:   []string{"/id: expected string, got null"},
+		},
+		{
+			name:   "Empty Struct",
+			schema: jsonschema.For[struct{}](),
+			data:   "",
+		},
+		{
+			name:   "Empty Without Required",
+			schema: jsonschema.For[UpdateOrder](),
+			data:   " ",
+		},
+		{
+			name:   "Empty With Required",
+			schema: jsonschema.For[PlaceOrder](),
+			data:   "",
+			want:   []string{"/: expected object, got null"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.schema.Validate([]byte(tt.data))
+			slices.Sort(got)
+			if !slices.Equal(tt.want, got) {
+				t.Errorf("unexpected violations:\nwant=%q\ngot= %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+// Package jsonutil contains JSON helpers shared by the adapters.
+package jsonutil
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// StringField returns the value of the top-level string field of the JSON object in data.
+// It returns an empty string if the field is missing.
+func StringField(data []byte, field string) (string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", fmt.Errorf("decoding field %q: %w", field, err)
+	}
+
+	var value string
+	if raw, ok := fields[field]; ok {
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return "", fmt.Errorf("decoding field %q: %w", field, err)
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,113 @@
+// Package singleflight provides a duplicate call suppression mechanism
+// where callers can individually give up waiting without cancelling the shared call.
+package singleflight
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// call is an in-flight or completed Do call.
+type call[V any] struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	dups    int
+
+	val      V
+	err      error
+	panicked any
+}
+
+// Group represents a class of work and forms a namespace in
+// which units of work can be executed with duplicate suppression.
+type Group[V any] struct {
+	mu sync.Mutex
+	m  map[string]*call[V]
+}
+
+// Result holds the results of Do.
+type Result[V any] struct {
+	Val V
+	Err error
+	// Shared reports whether the result was delivered to more than one caller.
+	Shared bool
+	// Joined reports whether the caller joined a call started by another caller.
+	Joined bool
+}
+
+// Do executes and returns the results of fn, making sure that only one execution
+// is in-flight for a given key at a time. If a duplicate comes in, the duplicate
+// caller waits for the original to complete and receives the same results.
+//
+// fn receives a context detached from the cancellation of the callers: it is cancelled
+// only when every caller waiting for the result has given up.
+// If ctx is done before fn completes, Do returns ctx.Err() to that caller only.
+func (g *Group[V]) Do(ctx context.Context, key string, fn func(context.Context) (V, error)) Result[V] {
+	g.mu.Lock()
+	if g.m == nil {
+		g.m = make(map[string]*call[V])
+	}
+	c, ok := g.m[key]
+	if ok {
+		c.waiters++
+		c.dups++
+	} else {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		c = &call[V]{done: make(chan struct{}), cancel: cancel, waiters: 1}
+		g.m[key] = c
+		go g.doCall(callCtx, c, key, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		if c.panicked != nil {
+			panic(c.panicked)
+		}
+		return Result[V]{Val: c.val, Err: c.err, Shared: c.dups > 0, Joined: ok}
+	case <-ctx.Done():
+		g.mu.Lock()
+		c.waiters--
+		if c.waiters == 0 {
+			c.cancel()
+			// new callers start a new call instead of joining the cancelled one
+			if g.m[key] == c {
+				delete(g.m, key)
+			}
+		}
+		g.mu.Unlock()
+
+		var zero V
+		return Result[V]{Val: zero, Err: ctx.Err(), Shared: ok, Joined: ok}
+	}
+}
+
+// InFlight reports whether a call for key is currently executing and can be joined.
+func (g *Group[V]) InFlight(key string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	_, ok := g.m[key]
+	return ok
+}
+
+func (g *Group[V]) doCall(ctx context.Context, c *call[V], key string, fn func(context.Context) (V, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.panicked = fmt.Errorf("singleflight: panic in shared call: %v", r)
+		}
+
+		g.mu.Lock()
+		if g.m[key] == c {
+			delete(g.m, key)
+		}
+		g.mu.Unlock()
+
+		c.cancel()
+		close(c.done)
+	}()
+
+	c.val, c.err = fn(ctx)
+}
@@ -0,0 +1,147 @@
+package singleflight
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDo(t *testing.T) {
+	var g Group[string]
+	var calls atomic.Int32
+
+	fn := func(context.Context) (string, error) {
+		calls.Add(1)
+		time.Sleep(50 * time.Millisecond)
+		return "ok", nil
+	}
+
+	var wg sync.WaitGroup
+	var shared atomic.Int32
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := g.Do(context.Background(), "key", fn)
+			if res.Val != "ok" || res.Err != nil {
+				t.Errorf("unexpected result: %+v", res)
+			}
+			if res.Shared {
+				shared.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if want, got := int32(1), calls.Load(); want != got {
+		t.Errorf("unexpected calls: want=%d, got=%d", want, got)
+	}
+	if want, got := int32(5), shared.Load(); want != got {
+		t.Errorf("unexpected shared results: want=%d, got=%d", want, got)
+	}
+}
+
+func TestDoCallerCancel(t *testing.T) {
+	var g Group[string]
+
+	release := make(chan struct{})
+	callErr := make(chan error, 1)
+	fn := func(ctx context.Context) (string, error) {
+		<-release
+		callErr <- ctx.Err()
+		return "ok", nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan Result[string])
+	go func() { done <- g.Do(ctx, "key", fn) }()
+	go func() { done <- g.Do(context.Background(), "key", fn) }()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	if res := <-done; !errors.Is(res.Err, context.Canceled) {
+		t.Errorf("unexpected error: want=%v, got=%v", context.Canceled, res.Err)
+	}
+
+	close(release)
+	if res := <-done; res.Val != "ok" {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	if err := <-callErr; err != nil {
+		t.Errorf("shared call cancelled: %v", err)
+	}
+}
+
+func TestDoAllCallersCancel(t *testing.T) {
+	var g Group[string]
+
+	callErr := make(chan error, 1)
+	fn := func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		callErr <- ctx.Err()
+		return "", ctx.Err()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_ = g.Do(ctx, "key", fn)
+
+	select {
+	case err := <-callErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("unexpected error: want=%v, got=%v", context.Canceled, err)
+		}
+	case <-time.After(time.Second):
+		t.Error("shared call not cancelled")
+	}
+}
+
+func TestDoAfterAllCallersCancel(t *testing.T) {
+	var g Group[string]
+
+	release, finished := make(chan struct{}), make(chan struct{})
+	slow := func(ctx context.Context) (string, error) {
+		defer close(finished)
+		<-release // ignores the cancellation for a while
+		return "", ctx.Err()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_ = g.Do(ctx, "key", slow)
+
+	if g.InFlight("key") {
+		t.Error("cancelled call still joinable")
+	}
+
+	res := g.Do(context.Background(), "key", func(context.Context) (string, error) { return "ok", nil })
+	if res.Val != "ok" || res.Err != nil || res.Joined {
+		t.Errorf("unexpected result: %+v", res)
+	}
+
+	// the cancelled call completing doesn't remove the new one
+	started, hold := make(chan struct{}), make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = g.Do(context.Background(), "key", func(context.Context) (string, error) {
+			close(started)
+			<-hold
+			return "ok", nil
+		})
+	}()
+	<-started
+	close(release)
+	<-finished
+	time.Sleep(10 * time.Millisecond)
+
+	if !g.InFlight("key") {
+		t.Error("new call removed by the cancelled one")
+	}
+	close(hold)
+	<-done
+}
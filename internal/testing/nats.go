@@ -13,10 +13,28 @@ import (
 func NewNATSServerAndConn(t *testing.T) (*server.Server, *nats.Conn) {
 	t.Helper()
 
-	s, err := server.NewServer(&server.Options{
+	return newServerAndConn(t, &server.Options{
 		Host: "localhost",
 		Port: server.RANDOM_PORT,
 	})
+}
+
+// NewJetStreamServerAndConn is like [NewNATSServerAndConn] but the server has JetStream enabled.
+func NewJetStreamServerAndConn(t *testing.T) (*server.Server, *nats.Conn) {
+	t.Helper()
+
+	return newServerAndConn(t, &server.Options{
+		Host:      "localhost",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+	})
+}
+
+func newServerAndConn(t *testing.T, opts *server.Options) (*server.Server, *nats.Conn) {
+	t.Helper()
+
+	s, err := server.NewServer(opts)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/mcosta74/hexkit/requests"
+)
+
+var (
+	// ErrMissingCredentials is reported when the request carries no credentials.
+	ErrMissingCredentials = errors.New("auth: missing credentials")
+
+	// ErrInvalidCredentials is reported when the credentials of the request are rejected.
+	ErrInvalidCredentials = errors.New("auth: invalid credentials")
+
+	// ErrInvalidToken is reported when a token is malformed, expired or not trusted.
+	ErrInvalidToken = errors.New("auth: invalid token")
+)
+
+// Error is returned by the middleware for the requests of unauthenticated callers.
+// The adapters report it with http.StatusUnauthorized and the micro code "401".
+type Error struct {
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode implements the StatusCoder interface of the HTTP adapter.
+func (e *Error) StatusCode() int {
+	return 401
+}
+
+// ErrorCode implements the ErrorCoder interface of the NATS adapters.
+func (e *Error) ErrorCode() string {
+	return "401"
+}
+
+// New returns a [requests.Middleware] which rejects the requests whose context doesn't
+// carry a principal (see [ContextWithPrincipal]) with an [*Error], wrapping the failure
+// stored in the context or [ErrMissingCredentials].
+func New[Req, Resp any]() requests.Middleware[Req, Resp] {
+	return func(next requests.Handler[Req, Resp]) requests.Handler[Req, Resp] {
+		return requests.HandlerFunc[Req, Resp](func(ctx context.Context, req Req) (Resp, error) {
+			if _, ok := PrincipalFromContext(ctx); ok {
+				return next.Handle(ctx, req)
+			}
+
+			err, ok := ErrorFromContext(ctx)
+			if !ok {
+				err = ErrMissingCredentials
+			}
+			var zero Resp
+			return zero, &Error{Err: err}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mcosta74/hexkit/requests"
+	"github.com/mcosta74/hexkit/requests/auth"
+)
+
+func TestMiddleware(t *testing.T) {
+	h := auth.New[string, string]()(requests.HandlerFunc[string, string](func(ctx context.Context, req string) (string, error) {
+		p, _ := auth.PrincipalFromContext(ctx)
+		return req + " " + p.Subject, nil
+	}))
+
+	t.Run("Authenticated", func(t *testing.T) {
+		ctx := auth.ContextWithPrincipal(context.Background(), &auth.Principal{Subject: "alice"})
+		resp, err := h.Handle(ctx, "hello")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "hello alice"; want != resp {
+			t.Errorf("unexpected response: want=%q, got=%q", want, resp)
+		}
+	})
+
+	t.Run("Missing Credentials", func(t *testing.T) {
+		_, err := h.Handle(context.Background(), "hello")
+
+		var ae *auth.Error
+		if !errors.As(err, &ae) || !errors.Is(err, auth.ErrMissingCredentials) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ae.StatusCode() != 401 || ae.ErrorCode() != "401" {
+			t.Errorf("unexpected codes: %d, %s", ae.StatusCode(), ae.ErrorCode())
+		}
+	})
+
+	t.Run("Rejected Credentials", func(t *testing.T) {
+		ctx := auth.ContextWithError(context.Background(), auth.ErrInvalidToken)
+		if _, err := h.Handle(ctx, "hello"); !errors.Is(err, auth.ErrInvalidToken) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestContextWithAuthentication(t *testing.T) {
+	shared := &auth.Principal{Subject: "alice"}
+	ctx := auth.ContextWithAuthentication(context.Background(), auth.MethodAPIKey, shared, nil)
+
+	p, ok := auth.PrincipalFromContext(ctx)
+	if !ok || p.Subject != "alice" || p.Method != auth.MethodAPIKey {
+		t.Errorf("unexpected principal: %+v", p)
+	}
+	if shared.Method != "" {
+		t.Errorf("unexpected method of the authenticated principal: %q", shared.Method)
+	}
+
+	ctx = auth.ContextWithAuthentication(context.Background(), auth.MethodAPIKey, nil, nil)
+	if err, _ := auth.ErrorFromContext(ctx); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestStaticAuthenticators(t *testing.T) {
+	ctx := context.Background()
+
+	keys := auth.StaticAPIKeys(map[string]string{"k1": "svc"})
+	if p, err := keys(ctx, "k1"); err != nil || p.Subject != "svc" || p.Method != auth.MethodAPIKey {
+		t.Errorf("unexpected result: %+v, %v", p, err)
+	}
+	if _, err := keys(ctx, "k2"); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	users := auth.StaticUsers(map[string]string{"alice": "secret"})
+	if p, err := users(ctx, "alice", "secret"); err != nil || p.Subject != "alice" || p.Method != auth.MethodBasic {
+		t.Errorf("unexpected result: %+v, %v", p, err)
+	}
+	for _, creds := range [][2]string{{"alice", "wrong"}, {"bob", ""}} {
+		if _, err := users(ctx, creds[0], creds[1]); !errors.Is(err, auth.ErrInvalidCredentials) {
+			t.Errorf("unexpected error for %v: %v", creds, err)
+		}
+	}
+}
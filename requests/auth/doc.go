@@ -0,0 +1,6 @@
+// Package auth provides a middleware rejecting the requests of unauthenticated callers.
+//
+// The transport adapters authenticate the credentials of the requests with RequestFuncs
+// (e.g. the bearer tokens verified by a [JWTVerifier]) and store the resulting [Principal]
+// in the context, where the middleware and the handlers find it.
+package auth
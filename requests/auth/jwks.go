@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// KeySet holds the keys trusted by a [JWTVerifier]: HMAC secrets ([]byte),
+// *rsa.PublicKey and *ecdsa.PublicKey.
+//
+// HMAC secrets must be at least as long as the output of the hash (RFC 7518, section 3.2):
+// 32 bytes, and 48 and 64 bytes to verify HS384 and HS512 tokens.
+type KeySet struct {
+	keys []jwk
+}
+
+// minHMACKeySize is the size of the shortest HMAC secret, for HS256.
+const minHMACKeySize = 32
+
+type jwk struct {
+	id  string
+	alg string
+	key any
+}
+
+// NewKeySet creates an empty key set.
+func NewKeySet() *KeySet {
+	return &KeySet{}
+}
+
+// Add adds a key identified by kid, which may be empty. Keys must not be added
+// once the set is used by a verifier.
+func (s *KeySet) Add(kid string, key any) error {
+	switch key := key.(type) {
+	case []byte:
+		if len(key) < minHMACKeySize {
+			return fmt.Errorf("auth: HMAC key shorter than %d bytes", minHMACKeySize)
+		}
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+	default:
+		return fmt.Errorf("auth: unsupported key type %T", key)
+	}
+	s.keys = append(s.keys, jwk{id: kid, key: key})
+	return nil
+}
+
+// LoadJWKS reads a key set from a JSON Web Key Set file, see [ParseJWKS].
+func LoadJWKS(path string) (*KeySet, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(b)
+}
+
+// ParseJWKS parses a JSON Web Key Set (RFC 7517) with "oct", "RSA" and "EC" keys.
+// Keys used for encryption ("use": "enc") are skipped.
+func ParseJWKS(data []byte) (*KeySet, error) {
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Alg string `json:"alg"`
+			Use string `json:"use"`
+			K   string `json:"k"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("auth: parsing key set: %w", err)
+	}
+
+	s := NewKeySet()
+	for i, k := range set.Keys {
+		if k.Use == "enc" {
+			continue
+		}
+
+		var (
+			key any
+			err error
+		)
+		switch k.Kty {
+		case "oct":
+			key, err = hmacKey(k.K, k.Alg)
+		case "RSA":
+			key, err = rsaKey(k.N, k.E)
+		case "EC":
+			key, err = ecKey(k.Crv, k.X, k.Y)
+		default:
+			err = fmt.Errorf("unsupported key type %q", k.Kty)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("auth: parsing key %d of key set: %w", i, err)
+		}
+		s.keys = append(s.keys, jwk{id: k.Kid, alg: k.Alg, key: key})
+	}
+	return s, nil
+}
+
+func hmacKey(k, alg string) ([]byte, error) {
+	key, err := decodeSegment(k)
+	if err != nil {
+		return nil, err
+	}
+	size := minHMACKeySize
+	if a, ok := algorithms[alg]; ok && a.kind == "HS" {
+		size = a.hash.Size()
+	}
+	if len(key) < size {
+		return nil, fmt.Errorf("HMAC key shorter than %d bytes", size)
+	}
+	return key, nil
+}
+
+func rsaKey(n, e string) (*rsa.PublicKey, error) {
+	nb, err := decodeSegment(n)
+	if err != nil {
+		return nil, err
+	}
+	eb, err := decodeSegment(e)
+	if err != nil {
+		return nil, err
+	}
+	exp := new(big.Int).SetBytes(eb)
+	if len(nb) == 0 || !exp.IsInt64() || exp.Int64() < 2 || exp.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("invalid RSA key")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(nb), E: int(exp.Int64())}, nil
+}
+
+func ecKey(crv, x, y string) (*ecdsa.PublicKey, error) {
+	var (
+		curve elliptic.Curve
+		point ecdh.Curve
+	)
+	switch crv {
+	case "P-256":
+		curve, point = elliptic.P256(), ecdh.P256()
+	case "P-384":
+		curve, point = elliptic.P384(), ecdh.P384()
+	case "P-521":
+		curve, point = elliptic.P521(), ecdh.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", crv)
+	}
+
+	xb, err := decodeSegment(x)
+	if err != nil {
+		return nil, err
+	}
+	yb, err := decodeSegment(y)
+	if err != nil {
+		return nil, err
+	}
+	size := (curve.Params().BitSize + 7) / 8
+	if len(xb) != size || len(yb) != size {
+		return nil, fmt.Errorf("invalid EC key")
+	}
+	// ecdh validates that the point is on the curve
+	if _, err := point.NewPublicKey(append(append([]byte{4}, xb...), yb...)); err != nil {
+		return nil, fmt.Errorf("invalid EC key: %w", err)
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(xb), Y: new(big.Int).SetBytes(yb)}, nil
+}
+
+// candidates returns the keys which may have signed a token with the given key id and algorithm.
+func (s *KeySet) candidates(kid, alg string) []any {
+	var keys []any
+	for _, k := range s.keys {
+		if (kid != "" && k.id != "" && k.id != kid) || (k.alg != "" && k.alg != alg) {
+			continue
+		}
+		keys = append(keys, k.key)
+	}
+	return keys
+}
+
+func decodeSegment(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
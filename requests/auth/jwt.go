@@ -0,0 +1,256 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// JWTVerifier verifies JSON Web Tokens (RFC 7519) signed with HS256, HS384, HS512,
+// RS256, RS384, RS512, ES256, ES384 or ES512 by the keys of a [KeySet].
+type JWTVerifier struct {
+	keys     *KeySet
+	issuer   string
+	audience string
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+// NewJWTVerifier creates a new verifier trusting the keys of the set.
+func NewJWTVerifier(keys *KeySet, options ...JWTOption) *JWTVerifier {
+	v := &JWTVerifier{
+		keys: keys,
+		now:  time.Now,
+	}
+
+	for _, o := range options {
+		o(v)
+	}
+	return v
+}
+
+// JWTOption sets optional parameter for the verifier.
+type JWTOption func(v *JWTVerifier)
+
+// WithIssuer makes the verifier require the "iss" claim.
+func WithIssuer(iss string) JWTOption {
+	return func(v *JWTVerifier) {
+		v.issuer = iss
+	}
+}
+
+// WithAudience makes the verifier require aud among the audiences of the "aud" claim.
+func WithAudience(aud string) JWTOption {
+	return func(v *JWTVerifier) {
+		v.audience = aud
+	}
+}
+
+// WithLeeway sets the clock skew tolerated checking the "exp" and "nbf" claims.
+func WithLeeway(d time.Duration) JWTOption {
+	return func(v *JWTVerifier) {
+		v.leeway = d
+	}
+}
+
+// WithClock sets the function returning the current time, time.Now by default.
+func WithClock(now func() time.Time) JWTOption {
+	return func(v *JWTVerifier) {
+		v.now = now
+	}
+}
+
+// Verify checks the signature and the registered claims of token and returns its claims.
+// Tokens with critical header parameters ("crit") are rejected, since no extension is
+// supported. The failures wrap [ErrInvalidToken].
+func (v *JWTVerifier) Verify(token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg  string   `json:"alg"`
+		Kid  string   `json:"kid"`
+		Crit []string `json:"crit"`
+	}
+	if err := decodeJSONSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+	if header.Crit != nil {
+		return nil, fmt.Errorf("%w: unsupported critical header parameters %q", ErrInvalidToken, header.Crit)
+	}
+	sig, err := decodeSegment(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %v", ErrInvalidToken, err)
+	}
+
+	alg, ok := algorithms[header.Alg]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, header.Alg)
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	if !slices.ContainsFunc(v.keys.candidates(header.Kid, header.Alg), func(key any) bool {
+		return alg.verify(key, signed, sig)
+	}) {
+		return nil, fmt.Errorf("%w: signature not verified", ErrInvalidToken)
+	}
+
+	var claims map[string]any
+	if err := decodeJSONSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+	if err := v.validate(claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	return claims, nil
+}
+
+// Authenticate implements TokenAuthenticator: the principal has the "sub" claim as subject.
+func (v *JWTVerifier) Authenticate(_ context.Context, token string) (*Principal, error) {
+	claims, err := v.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+	sub, _ := claims["sub"].(string)
+	return &Principal{Subject: sub, Method: MethodJWT, Claims: claims}, nil
+}
+
+func (v *JWTVerifier) validate(claims map[string]any) error {
+	now := v.now()
+
+	if exp, ok, err := numericDate(claims, "exp"); err != nil {
+		return err
+	} else if ok && !now.Before(exp.Add(v.leeway)) {
+		return fmt.Errorf("expired")
+	}
+	if nbf, ok, err := numericDate(claims, "nbf"); err != nil {
+		return err
+	} else if ok && now.Add(v.leeway).Before(nbf) {
+		return fmt.Errorf("not valid yet")
+	}
+
+	if v.issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != v.issuer {
+			return fmt.Errorf("unexpected issuer %q", iss)
+		}
+	}
+	if v.audience != "" && !hasAudience(claims["aud"], v.audience) {
+		return fmt.Errorf("audience %q missing", v.audience)
+	}
+	return nil
+}
+
+func numericDate(claims map[string]any, name string) (time.Time, bool, error) {
+	v, ok := claims[name]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return time.Time{}, false, fmt.Errorf("claim %q is not a number", name)
+	}
+	sec := int64(f)
+	return time.Unix(sec, int64((f-float64(sec))*1e9)), true, nil
+}
+
+func hasAudience(aud any, want string) bool {
+	switch aud := aud.(type) {
+	case string:
+		return aud == want
+	case []any:
+		for _, a := range aud {
+			if a == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeJSONSegment(s string, v any) error {
+	b, err := decodeSegment(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// algorithm verifies the signatures of an "alg" of RFC 7518.
+type algorithm struct {
+	hash crypto.Hash
+	kind string
+}
+
+var algorithms = map[string]algorithm{
+	"HS256": {crypto.SHA256, "HS"},
+	"HS384": {crypto.SHA384, "HS"},
+	"HS512": {crypto.SHA512, "HS"},
+	"RS256": {crypto.SHA256, "RS"},
+	"RS384": {crypto.SHA384, "RS"},
+	"RS512": {crypto.SHA512, "RS"},
+	"ES256": {crypto.SHA256, "ES"},
+	"ES384": {crypto.SHA384, "ES"},
+	"ES512": {crypto.SHA512, "ES"},
+}
+
+// verify reports whether sig is the signature of signed with key. The key type
+// must match the algorithm, so a public key is never used as an HMAC secret.
+func (a algorithm) verify(key any, signed, sig []byte) bool {
+	switch key := key.(type) {
+	case []byte:
+		if a.kind != "HS" || len(key) < a.hash.Size() {
+			return false
+		}
+		mac := hmac.New(a.hash.New, key)
+		mac.Write(signed)
+		return hmac.Equal(mac.Sum(nil), sig)
+
+	case *rsa.PublicKey:
+		if a.kind != "RS" {
+			return false
+		}
+		return rsa.VerifyPKCS1v15(key, a.hash, a.digest(signed), sig) == nil
+
+	case *ecdsa.PublicKey:
+		if a.kind != "ES" || key.Curve != a.curve() {
+			return false
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(sig) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(sig[:size])
+		s := new(big.Int).SetBytes(sig[size:])
+		return ecdsa.Verify(key, a.digest(signed), r, s)
+	}
+	return false
+}
+
+func (a algorithm) digest(signed []byte) []byte {
+	h := a.hash.New()
+	h.Write(signed)
+	return h.Sum(nil)
+}
+
+// curve returns the curve of the ES algorithms.
+func (a algorithm) curve() elliptic.Curve {
+	switch a.hash {
+	case crypto.SHA256:
+		return elliptic.P256()
+	case crypto.SHA384:
+		return elliptic.P384()
+	}
+	return elliptic.P521()
+}
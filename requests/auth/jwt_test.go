@@ -0,0 +1,212 @@
+package auth_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mcosta74/hexkit/requests/auth"
+)
+
+func segment(v any) string {
+	b, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// sign returns a token signed with key, which is a []byte, *rsa.PrivateKey or *ecdsa.PrivateKey (P-256).
+func sign(t *testing.T, kid string, key any, claims map[string]any) string {
+	t.Helper()
+
+	var alg string
+	switch key.(type) {
+	case []byte:
+		alg = "HS256"
+	case *rsa.PrivateKey:
+		alg = "RS256"
+	case *ecdsa.PrivateKey:
+		alg = "ES256"
+	}
+	signed := segment(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"}) + "." + segment(claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	switch key := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(signed))
+		sig = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		var err error
+		if sig, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:]); err != nil {
+			t.Fatal(err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+// withHeader returns token with header, signed with the HS256 secret.
+func withHeader(t *testing.T, token string, secret []byte, header map[string]any) string {
+	t.Helper()
+
+	_, rest, _ := strings.Cut(token, ".")
+	claims, _, _ := strings.Cut(rest, ".")
+	signed := segment(header) + "." + claims
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestJWTVerifier(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	jwks := fmt.Sprintf(`{"keys": [
+		{"kty": "oct", "kid": "hs", "k": %q},
+		{"kty": "RSA", "kid": "rs", "alg": "RS256", "n": %q, "e": %q},
+		{"kty": "EC", "kid": "es", "crv": "P-256", "x": %q, "y": %q},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": "", "e": ""}
+	]}`,
+		b64(secret),
+		b64(rsaKey.N.Bytes()), b64([]byte{1, 0, 1}),
+		b64(ecKey.X.FillBytes(make([]byte, 32))), b64(ecKey.Y.FillBytes(make([]byte, 32))),
+	)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, []byte(jwks), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := auth.LoadJWKS(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1700000000, 0)
+	v := auth.NewJWTVerifier(keys,
+		auth.WithIssuer("issuer"),
+		auth.WithAudience("api"),
+		auth.WithLeeway(time.Minute),
+		auth.WithClock(func() time.Time { return now }),
+	)
+	claims := func(extra map[string]any) map[string]any {
+		c := map[string]any{"sub": "alice", "iss": "issuer", "aud": []string{"web", "api"}, "exp": now.Add(time.Hour).Unix()}
+		for k, v := range extra {
+			c[k] = v
+		}
+		return c
+	}
+
+	for _, tc := range []struct {
+		name string
+		kid  string
+		key  any
+	}{
+		{"HS256", "hs", secret},
+		{"RS256", "rs", rsaKey},
+		{"ES256", "es", ecKey},
+		{"No Key ID", "", ecKey},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := v.Authenticate(context.Background(), sign(t, tc.kid, tc.key, claims(nil)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Subject != "alice" || p.Method != auth.MethodJWT || p.Claims["iss"] != "issuer" {
+				t.Errorf("unexpected principal: %+v", p)
+			}
+		})
+	}
+
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	hsToken := sign(t, "hs", secret, claims(nil))
+
+	for _, tc := range []struct {
+		name  string
+		token string
+	}{
+		{"Malformed", "abc.def"},
+		{"Unknown Key", sign(t, "es", otherKey, claims(nil))},
+		{"Wrong Key ID", sign(t, "rs", ecKey, claims(nil))},
+		{"Tampered", hsToken[:len(hsToken)-2] + "AA"},
+		{"Algorithm None", segment(map[string]string{"alg": "none"}) + "." + segment(claims(nil)) + "."},
+		{"Algorithm Confusion", sign(t, "", rsaKey.N.Bytes(), claims(nil))},
+		{"Critical Header", withHeader(t, hsToken, secret, map[string]any{"alg": "HS256", "kid": "hs", "crit": []string{"exp"}, "exp": 0})},
+		{"Expired", sign(t, "hs", secret, claims(map[string]any{"exp": now.Add(-2 * time.Minute).Unix()}))},
+		{"Not Valid Yet", sign(t, "hs", secret, claims(map[string]any{"nbf": now.Add(2 * time.Minute).Unix()}))},
+		{"Wrong Issuer", sign(t, "hs", secret, claims(map[string]any{"iss": "other"}))},
+		{"Wrong Audience", sign(t, "hs", secret, claims(map[string]any{"aud": "web"}))},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := v.Verify(tc.token); !errors.Is(err, auth.ErrInvalidToken) {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	t.Run("Leeway", func(t *testing.T) {
+		token := sign(t, "hs", secret, claims(map[string]any{"exp": now.Add(-30 * time.Second).Unix()}))
+		if _, err := v.Verify(token); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestKeySet(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+
+	keys := auth.NewKeySet()
+	if err := keys.Add("", "secret"); err == nil {
+		t.Error("string key accepted")
+	}
+	if err := keys.Add("", []byte("secret")); err == nil {
+		t.Error("short HMAC key accepted")
+	}
+	if err := keys.Add("", secret); err != nil {
+		t.Fatal(err)
+	}
+
+	token := sign(t, "any", secret, map[string]any{"sub": "bob"})
+	if _, err := auth.NewJWTVerifier(keys).Verify(token); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	b64 := base64.RawURLEncoding.EncodeToString
+	for _, jwks := range []string{
+		`{"keys": [{"kty": "EC", "crv": "P-256", "x": "AAAA", "y": "AAAA"}]}`,
+		`{"keys": [{"kty": "OKP"}]}`,
+		`{"keys": [{"kty": "oct", "k": "` + b64([]byte("secret")) + `"}]}`,
+		`{"keys": [{"kty": "oct", "alg": "HS512", "k": "` + b64(secret) + `"}]}`,
+		`not json`,
+	} {
+		if _, err := auth.ParseJWKS([]byte(jwks)); err == nil {
+			t.Errorf("key set accepted: %s", jwks)
+		}
+	}
+}
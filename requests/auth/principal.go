@@ -0,0 +1,104 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+)
+
+// Authentication methods, as reported by [Principal].
+const (
+	MethodJWT    = "jwt"
+	MethodAPIKey = "apikey"
+	MethodBasic  = "basic"
+	MethodNATS   = "nats"
+	// MethodNATSUntrusted reports NATS client information which may have been set by
+	// the client rather than by the server.
+	MethodNATSUntrusted = "nats-untrusted"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Subject identifies the caller, e.g. the user name or the "sub" claim of a JWT.
+	Subject string
+	// Method is the authentication method, e.g. [MethodJWT].
+	Method string
+	// Claims holds the other attributes of the caller, e.g. the claims of a JWT.
+	Claims map[string]any
+}
+
+type contextKey int
+
+const (
+	principalContextKey contextKey = iota
+	errorContextKey
+)
+
+// ContextWithPrincipal returns a copy of ctx carrying the principal.
+func ContextWithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey, p)
+}
+
+// PrincipalFromContext returns the principal stored in ctx, if any.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalContextKey).(*Principal)
+	return p, ok && p != nil
+}
+
+// ContextWithError returns a copy of ctx carrying the reason the credentials of the
+// request were rejected, reported by the middleware.
+func ContextWithError(ctx context.Context, err error) context.Context {
+	return context.WithValue(ctx, errorContextKey, err)
+}
+
+// ErrorFromContext returns the authentication failure stored in ctx, if any.
+func ErrorFromContext(ctx context.Context) (error, bool) {
+	err, ok := ctx.Value(errorContextKey).(error)
+	return err, ok && err != nil
+}
+
+// ContextWithAuthentication returns a copy of ctx carrying the result of an authenticator:
+// err if not nil, [ErrInvalidCredentials] if p is nil, the principal otherwise. A principal
+// without a method is stored as a copy of p with the given method, so p isn't modified.
+func ContextWithAuthentication(ctx context.Context, method string, p *Principal, err error) context.Context {
+	if err != nil {
+		return ContextWithError(ctx, err)
+	}
+	if p == nil {
+		return ContextWithError(ctx, ErrInvalidCredentials)
+	}
+	if p.Method == "" {
+		cp := *p
+		cp.Method = method
+		p = &cp
+	}
+	return ContextWithPrincipal(ctx, p)
+}
+
+// TokenAuthenticator returns the principal owning a token, e.g. a JWT or an API key.
+type TokenAuthenticator func(ctx context.Context, token string) (*Principal, error)
+
+// PasswordAuthenticator returns the principal with the given credentials.
+type PasswordAuthenticator func(ctx context.Context, username, password string) (*Principal, error)
+
+// StaticAPIKeys returns a TokenAuthenticator accepting the keys of the map, whose values are the subjects.
+func StaticAPIKeys(keys map[string]string) TokenAuthenticator {
+	return func(_ context.Context, token string) (*Principal, error) {
+		for key, subject := range keys {
+			if subtle.ConstantTimeCompare([]byte(key), []byte(token)) == 1 {
+				return &Principal{Subject: subject, Method: MethodAPIKey}, nil
+			}
+		}
+		return nil, ErrInvalidCredentials
+	}
+}
+
+// StaticUsers returns a PasswordAuthenticator accepting the users of the map, whose values are the passwords.
+func StaticUsers(users map[string]string) PasswordAuthenticator {
+	return func(_ context.Context, username, password string) (*Principal, error) {
+		want, ok := users[username]
+		if subtle.ConstantTimeCompare([]byte(want), []byte(password)) != 1 || !ok {
+			return nil, ErrInvalidCredentials
+		}
+		return &Principal{Subject: username, Method: MethodBasic}, nil
+	}
+}
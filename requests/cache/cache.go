@@ -0,0 +1,162 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mcosta74/hexkit/adapters"
+	"github.com/mcosta74/hexkit/internal/singleflight"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+// KeyFunc derives the cache key from the request.
+// If ok is false the request bypasses the cache.
+type KeyFunc[Req any] func(ctx context.Context, req Req) (key string, ok bool)
+
+// Option sets optional parameters for the cache middleware.
+type Option func(c *config)
+
+type config struct {
+	staleTTL     time.Duration
+	negativeTTL  time.Duration
+	negative     func(error) bool
+	errorHandler adapters.ErrorHandler
+}
+
+// WithStaleWhileRevalidate allows expired entries to be served for up to d
+// while a single background call refreshes them.
+func WithStaleWhileRevalidate(d time.Duration) Option {
+	return func(c *config) {
+		c.staleTTL = d
+	}
+}
+
+// WithNegativeCaching enables caching of the errors for which match returns true,
+// e.g. not found errors. Matching errors are cached for ttl.
+func WithNegativeCaching(ttl time.Duration, match func(error) bool) Option {
+	return func(c *config) {
+		c.negativeTTL = ttl
+		c.negative = match
+	}
+}
+
+// WithErrorHandler sets the handler for store errors and for the panics of the
+// background revalidations. Store errors never fail the request: the handler is
+// invoked as on a cache miss.
+func WithErrorHandler(eh adapters.ErrorHandler) Option {
+	return func(c *config) {
+		c.errorHandler = eh
+	}
+}
+
+// New returns a [requests.Middleware] that caches responses in store for ttl.
+//
+// Concurrent misses for the same key are coalesced into a single call to the
+// wrapped handler.
+func New[Req, Resp any](store Store[Resp], key KeyFunc[Req], ttl time.Duration, options ...Option) requests.Middleware[Req, Resp] {
+	cfg := config{
+		errorHandler: adapters.NewNoOpErrorHandler(),
+	}
+	for _, o := range options {
+		o(&cfg)
+	}
+
+	return func(next requests.Handler[Req, Resp]) requests.Handler[Req, Resp] {
+		return &cache[Req, Resp]{
+			next:   next,
+			store:  store,
+			key:    key,
+			ttl:    ttl,
+			config: cfg,
+		}
+	}
+}
+
+type cache[Req, Resp any] struct {
+	config
+	next  requests.Handler[Req, Resp]
+	store Store[Resp]
+	key   KeyFunc[Req]
+	ttl   time.Duration
+	group singleflight.Group[Entry[Resp]]
+}
+
+// Handle implements requests.Handler.
+func (c *cache[Req, Resp]) Handle(ctx context.Context, req Req) (Resp, error) {
+	key, ok := c.key(ctx, req)
+	if !ok {
+		return c.next.Handle(ctx, req)
+	}
+
+	entry, found, err := c.store.Get(ctx, key)
+	if err != nil {
+		c.errorHandler.Handle(ctx, err)
+	}
+
+	if found {
+		now := time.Now()
+		if entry.Fresh(now) {
+			return entry.Response, entry.Err
+		}
+		if entry.Usable(now) {
+			if !c.group.InFlight(key) {
+				go c.revalidate(context.WithoutCancel(ctx), key, req)
+			}
+			return entry.Response, entry.Err
+		}
+	}
+
+	res := c.group.Do(ctx, key, c.fetch(key, req))
+	if res.Err != nil {
+		var zero Resp
+		return zero, res.Err
+	}
+	return res.Val.Response, res.Val.Err
+}
+
+// revalidate refreshes a stale entry in the background, reporting the panics
+// of the next handler to the error handler.
+func (c *cache[Req, Resp]) revalidate(ctx context.Context, key string, req Req) {
+	defer func() {
+		if r := recover(); r != nil {
+			err, ok := r.(error)
+			if !ok {
+				err = fmt.Errorf("cache: panic revalidating %q: %v", key, r)
+			}
+			c.errorHandler.Handle(ctx, err)
+		}
+	}()
+	c.group.Do(ctx, key, c.fetch(key, req))
+}
+
+// fetch calls the next handler and stores the result.
+func (c *cache[Req, Resp]) fetch(key string, req Req) func(context.Context) (Entry[Resp], error) {
+	return func(ctx context.Context) (Entry[Resp], error) {
+		resp, err := c.next.Handle(ctx, req)
+
+		now := time.Now()
+		var entry Entry[Resp]
+		switch {
+		case err == nil:
+			entry = Entry[Resp]{
+				Response:   resp,
+				FreshUntil: now.Add(c.ttl),
+				StaleUntil: now.Add(c.ttl + c.staleTTL),
+			}
+		case c.negative != nil && c.negative(err):
+			entry = Entry[Resp]{
+				Err:        err,
+				FreshUntil: now.Add(c.negativeTTL),
+				StaleUntil: now.Add(c.negativeTTL),
+			}
+		default:
+			return entry, err
+		}
+
+		if err := c.store.Set(ctx, key, entry); err != nil {
+			c.errorHandler.Handle(ctx, err)
+		}
+		return entry, nil
+	}
+}
@@ -0,0 +1,224 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mcosta74/hexkit/adapters"
+	"github.com/mcosta74/hexkit/requests"
+	"github.com/mcosta74/hexkit/requests/cache"
+)
+
+func identityKey(_ context.Context, req string) (string, bool) {
+	return req, req != ""
+}
+
+func countingHandler(calls *atomic.Int32, delay time.Duration) requests.Handler[string, string] {
+	return requests.HandlerFunc[string, string](func(_ context.Context, req string) (string, error) {
+		n := calls.Add(1)
+		time.Sleep(delay)
+		return req + "-" + string(rune('0'+n)), nil
+	})
+}
+
+func TestCache(t *testing.T) {
+	t.Run("Hit", func(t *testing.T) {
+		var calls atomic.Int32
+		h := cache.New(cache.NewMemoryStore[string](0), identityKey, time.Minute)(countingHandler(&calls, 0))
+
+		for range 3 {
+			resp, err := h.Handle(context.Background(), "a")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, got := "a-1", resp; want != got {
+				t.Errorf("unexpected response: want=%q, got=%q", want, got)
+			}
+		}
+		if want, got := int32(1), calls.Load(); want != got {
+			t.Errorf("unexpected calls: want=%d, got=%d", want, got)
+		}
+	})
+
+	t.Run("Bypass", func(t *testing.T) {
+		var calls atomic.Int32
+		h := cache.New(cache.NewMemoryStore[string](0), identityKey, time.Minute)(countingHandler(&calls, 0))
+
+		_, _ = h.Handle(context.Background(), "")
+		_, _ = h.Handle(context.Background(), "")
+
+		if want, got := int32(2), calls.Load(); want != got {
+			t.Errorf("unexpected calls: want=%d, got=%d", want, got)
+		}
+	})
+
+	t.Run("Expiration", func(t *testing.T) {
+		var calls atomic.Int32
+		h := cache.New(cache.NewMemoryStore[string](0), identityKey, 20*time.Millisecond)(countingHandler(&calls, 0))
+
+		_, _ = h.Handle(context.Background(), "a")
+		time.Sleep(40 * time.Millisecond)
+		resp, _ := h.Handle(context.Background(), "a")
+
+		if want, got := "a-2", resp; want != got {
+			t.Errorf("unexpected response: want=%q, got=%q", want, got)
+		}
+	})
+
+	t.Run("Errors Not Cached", func(t *testing.T) {
+		var calls atomic.Int32
+		h := cache.New(cache.NewMemoryStore[string](0), identityKey, time.Minute)(
+			requests.HandlerFunc[string, string](func(context.Context, string) (string, error) {
+				calls.Add(1)
+				return "", errors.New("fail")
+			}),
+		)
+
+		_, _ = h.Handle(context.Background(), "a")
+		_, err := h.Handle(context.Background(), "a")
+
+		if err == nil {
+			t.Error("expected error")
+		}
+		if want, got := int32(2), calls.Load(); want != got {
+			t.Errorf("unexpected calls: want=%d, got=%d", want, got)
+		}
+	})
+
+	t.Run("Negative Caching", func(t *testing.T) {
+		errNotFound := errors.New("not found")
+
+		var calls atomic.Int32
+		h := cache.New(
+			cache.NewMemoryStore[string](0),
+			identityKey,
+			time.Minute,
+			cache.WithNegativeCaching(time.Minute, func(err error) bool { return errors.Is(err, errNotFound) }),
+		)(
+			requests.HandlerFunc[string, string](func(context.Context, string) (string, error) {
+				calls.Add(1)
+				return "", errNotFound
+			}),
+		)
+
+		_, _ = h.Handle(context.Background(), "a")
+		_, err := h.Handle(context.Background(), "a")
+
+		if !errors.Is(err, errNotFound) {
+			t.Errorf("unexpected error: want=%v, got=%v", errNotFound, err)
+		}
+		if want, got := int32(1), calls.Load(); want != got {
+			t.Errorf("unexpected calls: want=%d, got=%d", want, got)
+		}
+	})
+
+	t.Run("Stale While Revalidate", func(t *testing.T) {
+		var calls atomic.Int32
+		h := cache.New(
+			cache.NewMemoryStore[string](0),
+			identityKey,
+			20*time.Millisecond,
+			cache.WithStaleWhileRevalidate(time.Minute),
+		)(countingHandler(&calls, 0))
+
+		_, _ = h.Handle(context.Background(), "a")
+		time.Sleep(40 * time.Millisecond)
+
+		resp, _ := h.Handle(context.Background(), "a")
+		if want, got := "a-1", resp; want != got {
+			t.Errorf("unexpected stale response: want=%q, got=%q", want, got)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		resp, _ = h.Handle(context.Background(), "a")
+		if want, got := "a-2", resp; want != got {
+			t.Errorf("unexpected revalidated response: want=%q, got=%q", want, got)
+		}
+	})
+
+	t.Run("Revalidation Panic", func(t *testing.T) {
+		var calls atomic.Int32
+		errs := make(chan error, 1)
+		h := cache.New(
+			cache.NewMemoryStore[string](0),
+			identityKey,
+			10*time.Millisecond,
+			cache.WithStaleWhileRevalidate(time.Minute),
+			cache.WithErrorHandler(adapters.ErrorHandlerFunc(func(_ context.Context, err error) { errs <- err })),
+		)(requests.HandlerFunc[string, string](func(_ context.Context, req string) (string, error) {
+			if calls.Add(1) > 1 {
+				panic("boom")
+			}
+			return req, nil
+		}))
+
+		_, _ = h.Handle(context.Background(), "a")
+		time.Sleep(20 * time.Millisecond)
+
+		if resp, _ := h.Handle(context.Background(), "a"); resp != "a" {
+			t.Errorf("unexpected stale response: %q", resp)
+		}
+		select {
+		case err := <-errs:
+			if !strings.Contains(err.Error(), "boom") {
+				t.Errorf("unexpected error: %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Error("panic not reported")
+		}
+	})
+
+	t.Run("Coalescing", func(t *testing.T) {
+		var calls atomic.Int32
+		h := cache.New(cache.NewMemoryStore[string](0), identityKey, time.Minute)(countingHandler(&calls, 50*time.Millisecond))
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if resp, _ := h.Handle(context.Background(), "a"); resp != "a-1" {
+					t.Errorf("unexpected response: want=%q, got=%q", "a-1", resp)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if want, got := int32(1), calls.Load(); want != got {
+			t.Errorf("unexpected calls: want=%d, got=%d", want, got)
+		}
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	store := cache.NewMemoryStore[string](2)
+	entry := func(v string) cache.Entry[string] {
+		return cache.Entry[string]{Response: v, FreshUntil: time.Now().Add(time.Minute)}
+	}
+
+	_ = store.Set(ctx, "a", entry("a"))
+	_ = store.Set(ctx, "b", entry("b"))
+	_, _, _ = store.Get(ctx, "a")
+	_ = store.Set(ctx, "c", entry("c"))
+
+	if _, ok, _ := store.Get(ctx, "b"); ok {
+		t.Error("expected least recently used entry to be evicted")
+	}
+	if _, ok, _ := store.Get(ctx, "a"); !ok {
+		t.Error("expected recently used entry to be kept")
+	}
+	if want, got := 2, store.Len(); want != got {
+		t.Errorf("unexpected len: want=%d, got=%d", want, got)
+	}
+
+	_ = store.Delete(ctx, "a")
+	if _, ok, _ := store.Get(ctx, "a"); ok {
+		t.Error("expected deleted entry to be missing")
+	}
+}
@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Entry is a cached result.
+type Entry[Resp any] struct {
+	// Response is the cached response.
+	Response Resp
+	// Err is the cached error when negative caching is enabled; Response is the zero value in that case.
+	Err error
+	// FreshUntil is the time until which the entry is served without revalidation.
+	FreshUntil time.Time
+	// StaleUntil is the time until which the entry may be served while it's being revalidated.
+	StaleUntil time.Time
+}
+
+// Fresh reports whether the entry can be served as is.
+func (e Entry[Resp]) Fresh(now time.Time) bool {
+	return now.Before(e.FreshUntil)
+}
+
+// Usable reports whether the entry can be served, possibly as stale.
+func (e Entry[Resp]) Usable(now time.Time) bool {
+	return e.Fresh(now) || now.Before(e.StaleUntil)
+}
+
+// Store is the storage used by the cache middleware.
+type Store[Resp any] interface {
+	// Get returns the entry stored for key; ok is false when there is no such entry.
+	Get(ctx context.Context, key string) (entry Entry[Resp], ok bool, err error)
+	// Set stores the entry for key.
+	Set(ctx context.Context, key string, entry Entry[Resp]) error
+	// Delete removes the entry for key, if any.
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryStore is an in-memory [Store] which evicts the least recently used entries
+// when it's full. Entries which are no longer usable are dropped on access.
+type MemoryStore[Resp any] struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+}
+
+type memoryItem[Resp any] struct {
+	key   string
+	entry Entry[Resp]
+}
+
+// NewMemoryStore creates an in-memory store holding up to maxEntries entries.
+// If maxEntries is zero or negative the store is unbounded.
+func NewMemoryStore[Resp any](maxEntries int) *MemoryStore[Resp] {
+	return &MemoryStore[Resp]{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Get implements [Store].
+func (s *MemoryStore[Resp]) Get(_ context.Context, key string) (Entry[Resp], bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[key]
+	if !ok {
+		return Entry[Resp]{}, false, nil
+	}
+
+	item := el.Value.(*memoryItem[Resp])
+	if !item.entry.Usable(time.Now()) {
+		s.removeElement(el)
+		return Entry[Resp]{}, false, nil
+	}
+
+	s.ll.MoveToFront(el)
+	return item.entry, true, nil
+}
+
+// Set implements [Store].
+func (s *MemoryStore[Resp]) Set(_ context.Context, key string, entry Entry[Resp]) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[key]; ok {
+		el.Value.(*memoryItem[Resp]).entry = entry
+		s.ll.MoveToFront(el)
+		return nil
+	}
+
+	s.items[key] = s.ll.PushFront(&memoryItem[Resp]{key: key, entry: entry})
+	if s.maxEntries > 0 && s.ll.Len() > s.maxEntries {
+		s.removeElement(s.ll.Back())
+	}
+	return nil
+}
+
+// Delete implements [Store].
+func (s *MemoryStore[Resp]) Delete(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if el, ok := s.items[key]; ok {
+		s.removeElement(el)
+	}
+	return nil
+}
+
+// Len returns the number of entries in the store.
+func (s *MemoryStore[Resp]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ll.Len()
+}
+
+func (s *MemoryStore[Resp]) removeElement(el *list.Element) {
+	s.ll.Remove(el)
+	delete(s.items, el.Value.(*memoryItem[Resp]).key)
+}
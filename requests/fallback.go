@@ -0,0 +1,82 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// Fallback returns a [Handler] which calls primary and, when it fails with an error
+// for which match returns true, calls the fallbacks in order until one of them succeeds
+// or fails with an error not matching.
+//
+// The handler which served the request can be retrieved through [RecordServedBy].
+func Fallback[Req, Resp any](primary Handler[Req, Resp], match func(error) bool, fallbacks ...Handler[Req, Resp]) Handler[Req, Resp] {
+	handlers := append([]Handler[Req, Resp]{primary}, fallbacks...)
+
+	return HandlerFunc[Req, Resp](func(ctx context.Context, req Req) (resp Resp, err error) {
+		for i, h := range handlers {
+			resp, err = h.Handle(ctx, req)
+			if err == nil || !match(err) || i == len(handlers)-1 {
+				if sb, ok := ServedByFromContext(ctx); ok {
+					sb.index.Store(int32(i))
+				}
+				return resp, err
+			}
+		}
+		return resp, err
+	})
+}
+
+// WithFallback returns a [Middleware] which uses the wrapped handler as primary of a [Fallback].
+func WithFallback[Req, Resp any](match func(error) bool, fallbacks ...Handler[Req, Resp]) Middleware[Req, Resp] {
+	return func(next Handler[Req, Resp]) Handler[Req, Resp] {
+		return Fallback(next, match, fallbacks...)
+	}
+}
+
+// MatchErrors returns a predicate matching the errors which wrap any of targets.
+func MatchErrors(targets ...error) func(error) bool {
+	return func(err error) bool {
+		for _, target := range targets {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+type contextKey int
+
+const servedByContextKey contextKey = iota
+
+// ServedBy records which handler of a [Fallback] served a request.
+type ServedBy struct {
+	index atomic.Int32
+}
+
+// RecordServedBy returns a copy of ctx which makes a [Fallback] record the handler
+// serving the request in the returned ServedBy.
+func RecordServedBy(ctx context.Context) (context.Context, *ServedBy) {
+	sb := &ServedBy{}
+	sb.index.Store(-1)
+	return context.WithValue(ctx, servedByContextKey, sb), sb
+}
+
+// Index returns 0 if the request was served by the primary handler, i if it was served
+// by the i-th fallback, -1 if it didn't go through a Fallback.
+func (sb *ServedBy) Index() int {
+	return int(sb.index.Load())
+}
+
+// Fallback reports whether the request was served by a fallback handler.
+func (sb *ServedBy) Fallback() bool {
+	return sb.Index() > 0
+}
+
+// ServedByFromContext returns the ServedBy installed in ctx by [RecordServedBy], if any.
+func ServedByFromContext(ctx context.Context) (*ServedBy, bool) {
+	sb, ok := ctx.Value(servedByContextKey).(*ServedBy)
+	return sb, ok
+}
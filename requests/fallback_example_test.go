@@ -0,0 +1,35 @@
+package requests_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mcosta74/hexkit/requests"
+)
+
+func ExampleFallback() {
+	errUnavailable := errors.New("unavailable")
+
+	primary := requests.HandlerFunc[string, string](func(context.Context, string) (string, error) {
+		return "", errUnavailable
+	})
+	secondary := requests.HandlerFunc[string, string](func(_ context.Context, req string) (string, error) {
+		return "hello " + req + " from secondary", nil
+	})
+
+	h := requests.Fallback(primary, requests.MatchErrors(errUnavailable), secondary)
+
+	ctx, servedBy := requests.RecordServedBy(context.Background())
+	resp, err := h.Handle(ctx, "world")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(resp)
+	fmt.Println("served by:", servedBy.Index())
+
+	// Output:
+	// hello world from secondary
+	// served by: 1
+}
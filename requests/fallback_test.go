@@ -0,0 +1,77 @@
+package requests_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mcosta74/hexkit/requests"
+)
+
+func TestFallback(t *testing.T) {
+	errUnavailable := errors.New("unavailable")
+	errInvalid := errors.New("invalid")
+
+	failing := func(err error) requests.Handler[string, string] {
+		return requests.HandlerFunc[string, string](func(context.Context, string) (string, error) { return "", err })
+	}
+	serving := func(resp string) requests.Handler[string, string] {
+		return requests.HandlerFunc[string, string](func(context.Context, string) (string, error) { return resp, nil })
+	}
+
+	tests := []struct {
+		name      string
+		handler   requests.Handler[string, string]
+		wantResp  string
+		wantErr   error
+		wantIndex int
+	}{
+		{
+			name:      "Primary",
+			handler:   requests.Fallback(serving("primary"), requests.MatchErrors(errUnavailable), serving("fallback")),
+			wantResp:  "primary",
+			wantIndex: 0,
+		},
+		{
+			name:      "Second Fallback",
+			handler:   requests.Fallback(failing(errUnavailable), requests.MatchErrors(errUnavailable), failing(errUnavailable), serving("fallback")),
+			wantResp:  "fallback",
+			wantIndex: 2,
+		},
+		{
+			name:      "Not Matching",
+			handler:   requests.Fallback(failing(errInvalid), requests.MatchErrors(errUnavailable), serving("fallback")),
+			wantErr:   errInvalid,
+			wantIndex: 0,
+		},
+		{
+			name:      "All Failing",
+			handler:   requests.Fallback(failing(errUnavailable), requests.MatchErrors(errUnavailable), failing(errUnavailable)),
+			wantErr:   errUnavailable,
+			wantIndex: 1,
+		},
+		{
+			name:      "Middleware",
+			handler:   requests.WithFallback(requests.MatchErrors(errUnavailable), serving("fallback"))(failing(errUnavailable)),
+			wantResp:  "fallback",
+			wantIndex: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, servedBy := requests.RecordServedBy(context.Background())
+
+			resp, err := tt.handler.Handle(ctx, "req")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("unexpected error: want=%v, got=%v", tt.wantErr, err)
+			}
+			if want, got := tt.wantResp, resp; want != got {
+				t.Errorf("unexpected response: want=%q, got=%q", want, got)
+			}
+			if want, got := tt.wantIndex, servedBy.Index(); want != got {
+				t.Errorf("unexpected served by: want=%d, got=%d", want, got)
+			}
+		})
+	}
+}
@@ -26,7 +26,11 @@ type Middleware[Req, Resp any] func(Handler[Req, Resp]) Handler[Req, Resp]
 // Requests will traverse the middleware in the order they are declared: the first middleware
 // is treated as the outermost one.
 func Chain[Req, Resp any](outer Middleware[Req, Resp], others ...Middleware[Req, Resp]) Middleware[Req, Resp] {
-	return func(next Handler[Req, Resp]) Handler[Req, Resp] {
+	return chain(outer, others)
+}
+
+func chain[M ~func(H) H, H any](outer M, others []M) M {
+	return func(next H) H {
 		for _, mdw := range slices.Backward(others) {
 			next = mdw(next)
 		}
@@ -0,0 +1,3 @@
+// Package hedge provides a middleware that reduces tail latency of idempotent
+// request handlers by sending additional attempts when the first one is slow.
+package hedge
@@ -0,0 +1,195 @@
+package hedge
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/mcosta74/hexkit/requests"
+)
+
+// Stats collects the counters of a hedging middleware.
+type Stats struct {
+	requests atomic.Uint64
+	hedges   atomic.Uint64
+}
+
+// Requests returns the number of requests handled by the middleware.
+func (s *Stats) Requests() uint64 {
+	return s.requests.Load()
+}
+
+// Hedges returns the number of additional attempts sent.
+func (s *Stats) Hedges() uint64 {
+	return s.hedges.Load()
+}
+
+// Option sets optional parameters for the hedging middleware.
+type Option func(c *config)
+
+type config struct {
+	maxAttempts  int
+	percentile   float64
+	window       int
+	minSamples   int
+	initialDelay time.Duration
+	ratio        float64
+	burst        int
+	stats        *Stats
+}
+
+// WithMaxAttempts sets the maximum number of attempts per request, the first one included.
+// The default is 2; values below 1 are raised to 1.
+func WithMaxAttempts(n int) Option {
+	return func(c *config) {
+		c.maxAttempts = n
+	}
+}
+
+// WithPercentile sets the latency percentile, in the range (0, 1], after which an
+// additional attempt is sent. The default is 0.95; values outside the range are clamped to it.
+func WithPercentile(p float64) Option {
+	return func(c *config) {
+		c.percentile = p
+	}
+}
+
+// WithWindow sets the number of recent latencies the percentile is computed on,
+// and the minimum number of samples required before using it. The defaults are 1000 and 100;
+// size is raised to at least 1 and minSamples is clamped to [1, size].
+func WithWindow(size, minSamples int) Option {
+	return func(c *config) {
+		c.window = size
+		c.minSamples = minSamples
+	}
+}
+
+// WithInitialDelay sets the delay used until enough latencies are collected.
+// The default is 100ms.
+func WithInitialDelay(d time.Duration) Option {
+	return func(c *config) {
+		c.initialDelay = d
+	}
+}
+
+// WithBudget limits the additional attempts to ratio of the requests (e.g. 0.1 for 10%),
+// allowing bursts of up to burst hedges. The defaults are 0.1 and 10.
+func WithBudget(ratio float64, burst int) Option {
+	return func(c *config) {
+		c.ratio = ratio
+		c.burst = burst
+	}
+}
+
+// WithStats sets the counters updated by the middleware.
+func WithStats(stats *Stats) Option {
+	return func(c *config) {
+		c.stats = stats
+	}
+}
+
+// New returns a [requests.Middleware] which sends an additional attempt to the wrapped
+// handler when the previous one hasn't returned within a delay derived from the observed
+// latency percentile. The first successful response is returned and the other attempts
+// are cancelled. If every attempt in flight fails, the last error is returned.
+//
+// The latency of every successful attempt is recorded, including the attempts completing
+// after another one won, so that the percentile isn't biased towards the fastest attempts.
+//
+// The wrapped handler must be idempotent.
+func New[Req, Resp any](options ...Option) requests.Middleware[Req, Resp] {
+	cfg := config{
+		maxAttempts:  2,
+		percentile:   0.95,
+		window:       1000,
+		minSamples:   100,
+		initialDelay: 100 * time.Millisecond,
+		ratio:        0.1,
+		burst:        10,
+		stats:        &Stats{},
+	}
+	for _, o := range options {
+		o(&cfg)
+	}
+	cfg.maxAttempts = max(cfg.maxAttempts, 1)
+	cfg.window = max(cfg.window, 1)
+	cfg.minSamples = min(max(cfg.minSamples, 1), cfg.window)
+	if cfg.percentile <= 0 || cfg.percentile > 1 {
+		cfg.percentile = min(max(cfg.percentile, 0.01), 1)
+	}
+
+	return func(next requests.Handler[Req, Resp]) requests.Handler[Req, Resp] {
+		return &hedger[Req, Resp]{
+			config:    cfg,
+			next:      next,
+			latencies: newLatencies(cfg.window, cfg.minSamples, cfg.percentile, cfg.initialDelay),
+			budget:    newBudget(cfg.ratio, cfg.burst),
+		}
+	}
+}
+
+type hedger[Req, Resp any] struct {
+	config
+	next      requests.Handler[Req, Resp]
+	latencies *latencies
+	budget    *budget
+}
+
+type attempt[Resp any] struct {
+	resp Resp
+	err  error
+}
+
+// Handle implements requests.Handler.
+func (h *hedger[Req, Resp]) Handle(ctx context.Context, req Req) (Resp, error) {
+	h.stats.requests.Add(1)
+	h.budget.Deposit()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan attempt[Resp], h.maxAttempts)
+	launch := func() {
+		go func() {
+			start := time.Now()
+			resp, err := h.next.Handle(ctx, req)
+			if err == nil {
+				h.latencies.Record(time.Since(start))
+			}
+			results <- attempt[Resp]{resp: resp, err: err}
+		}()
+	}
+
+	launch()
+	attempts, inflight := 1, 1
+
+	delay := h.latencies.Delay()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var zero Resp
+	for {
+		select {
+		case r := <-results:
+			inflight--
+			if r.err == nil {
+				return r.resp, nil
+			}
+			if inflight == 0 {
+				return zero, r.err
+			}
+
+		case <-timer.C:
+			if attempts < h.maxAttempts && h.budget.Withdraw() {
+				h.stats.hedges.Add(1)
+				launch()
+				attempts++
+				inflight++
+				timer.Reset(delay)
+			}
+
+		case <-ctx.Done():
+			return zero, ctx.Err()
+		}
+	}
+}
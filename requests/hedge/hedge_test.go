@@ -0,0 +1,162 @@
+package hedge_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mcosta74/hexkit/requests"
+	"github.com/mcosta74/hexkit/requests/hedge"
+)
+
+// slowFirst returns a handler whose first attempt blocks until cancelled.
+func slowFirst(calls *atomic.Int32, cancelled chan<- error) requests.Handler[string, string] {
+	return requests.HandlerFunc[string, string](func(ctx context.Context, req string) (string, error) {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			cancelled <- ctx.Err()
+			return "", ctx.Err()
+		}
+		return "hello " + req, nil
+	})
+}
+
+func TestHedge(t *testing.T) {
+	t.Run("Hedged", func(t *testing.T) {
+		var calls atomic.Int32
+		var stats hedge.Stats
+		cancelled := make(chan error, 1)
+
+		h := hedge.New[string, string](
+			hedge.WithInitialDelay(10*time.Millisecond),
+			hedge.WithBudget(1, 1),
+			hedge.WithStats(&stats),
+		)(slowFirst(&calls, cancelled))
+
+		resp, err := h.Handle(context.Background(), "world")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := "hello world", resp; want != got {
+			t.Errorf("unexpected response: want=%q, got=%q", want, got)
+		}
+		if want, got := uint64(1), stats.Hedges(); want != got {
+			t.Errorf("unexpected hedges: want=%d, got=%d", want, got)
+		}
+
+		select {
+		case err := <-cancelled:
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("unexpected error: want=%v, got=%v", context.Canceled, err)
+			}
+		case <-time.After(time.Second):
+			t.Error("slow attempt not cancelled")
+		}
+	})
+
+	t.Run("Fast", func(t *testing.T) {
+		var stats hedge.Stats
+
+		h := hedge.New[string, string](
+			hedge.WithInitialDelay(50*time.Millisecond),
+			hedge.WithBudget(1, 1),
+			hedge.WithStats(&stats),
+		)(requests.HandlerFunc[string, string](func(context.Context, string) (string, error) { return "ok", nil }))
+
+		for range 5 {
+			_, _ = h.Handle(context.Background(), "world")
+		}
+
+		if want, got := uint64(5), stats.Requests(); want != got {
+			t.Errorf("unexpected requests: want=%d, got=%d", want, got)
+		}
+		if want, got := uint64(0), stats.Hedges(); want != got {
+			t.Errorf("unexpected hedges: want=%d, got=%d", want, got)
+		}
+	})
+
+	t.Run("Budget Exhausted", func(t *testing.T) {
+		var stats hedge.Stats
+
+		h := hedge.New[string, string](
+			hedge.WithInitialDelay(time.Millisecond),
+			hedge.WithBudget(0.5, 1),
+			hedge.WithStats(&stats),
+		)(requests.HandlerFunc[string, string](func(context.Context, string) (string, error) {
+			time.Sleep(10 * time.Millisecond)
+			return "ok", nil
+		}))
+
+		for range 4 {
+			_, _ = h.Handle(context.Background(), "world")
+		}
+
+		if want, got := uint64(2), stats.Hedges(); want != got {
+			t.Errorf("unexpected hedges: want=%d, got=%d", want, got)
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		h := hedge.New[string, string](hedge.WithInitialDelay(time.Second))(
+			requests.HandlerFunc[string, string](func(context.Context, string) (string, error) { return "", errors.New("fail") }),
+		)
+
+		if _, err := h.Handle(context.Background(), "world"); err == nil || err.Error() != "fail" {
+			t.Errorf("unexpected error: want=%q, got=%v", "fail", err)
+		}
+	})
+
+	t.Run("Losing Attempts Recorded", func(t *testing.T) {
+		var calls atomic.Int32
+		var warm atomic.Bool
+		var stats hedge.Stats
+
+		// while warming up, the first attempts complete after the hedges: only their
+		// latencies raise the delay above the 20ms of the following requests
+		h := hedge.New[string, string](
+			hedge.WithWindow(16, 16),
+			hedge.WithPercentile(1),
+			hedge.WithInitialDelay(5*time.Millisecond),
+			hedge.WithBudget(1, 100),
+			hedge.WithStats(&stats),
+		)(requests.HandlerFunc[string, string](func(context.Context, string) (string, error) {
+			switch {
+			case warm.Load():
+				time.Sleep(20 * time.Millisecond)
+			case calls.Add(1)%2 == 1:
+				time.Sleep(100 * time.Millisecond)
+			}
+			return "ok", nil
+		}))
+
+		for range 8 {
+			if _, err := h.Handle(context.Background(), "world"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		time.Sleep(200 * time.Millisecond)
+
+		warm.Store(true)
+		hedges := stats.Hedges()
+		_, _ = h.Handle(context.Background(), "world")
+		if want, got := hedges, stats.Hedges(); want != got {
+			t.Errorf("unexpected hedges: want=%d, got=%d", want, got)
+		}
+	})
+
+	t.Run("Invalid Options", func(t *testing.T) {
+		h := hedge.New[string, string](
+			hedge.WithMaxAttempts(0),
+			hedge.WithWindow(0, 0),
+			hedge.WithPercentile(2),
+		)(requests.HandlerFunc[string, string](func(context.Context, string) (string, error) { return "ok", nil }))
+
+		for range 20 {
+			if resp, err := h.Handle(context.Background(), "world"); err != nil || resp != "ok" {
+				t.Fatalf("unexpected result: %q, %v", resp, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,96 @@
+package hedge
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// latencies keeps a window of recent latencies and derives the hedging delay from them.
+type latencies struct {
+	mu         sync.Mutex
+	percentile float64
+	minSamples int
+	fallback   time.Duration
+	samples    []time.Duration
+	next       int
+	recorded   int
+	delay      time.Duration
+}
+
+// refreshEvery is the number of samples after which the delay is recomputed.
+const refreshEvery = 16
+
+func newLatencies(window, minSamples int, percentile float64, fallback time.Duration) *latencies {
+	return &latencies{
+		percentile: percentile,
+		minSamples: minSamples,
+		fallback:   fallback,
+		samples:    make([]time.Duration, 0, window),
+		delay:      fallback,
+	}
+}
+
+// Record adds a sample to the window.
+func (l *latencies) Record(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.samples) < cap(l.samples) {
+		l.samples = append(l.samples, d)
+	} else {
+		l.samples[l.next] = d
+		l.next = (l.next + 1) % len(l.samples)
+	}
+
+	l.recorded++
+	if len(l.samples) >= l.minSamples && l.recorded%refreshEvery == 0 {
+		sorted := slices.Clone(l.samples)
+		slices.Sort(sorted)
+		idx := int(l.percentile * float64(len(sorted)-1))
+		l.delay = sorted[idx]
+	}
+}
+
+// Delay returns the current hedging delay.
+func (l *latencies) Delay() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.delay
+}
+
+// budget is a token bucket limiting hedges to a ratio of the requests.
+type budget struct {
+	mu     sync.Mutex
+	ratio  float64
+	tokens float64
+	max    float64
+}
+
+func newBudget(ratio float64, burst int) *budget {
+	return &budget{
+		ratio: ratio,
+		max:   float64(burst),
+	}
+}
+
+// Deposit credits a request to the budget.
+func (b *budget) Deposit() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = min(b.max, b.tokens+b.ratio)
+}
+
+// Withdraw reports whether a hedge is allowed, consuming the budget if it is.
+func (b *budget) Withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
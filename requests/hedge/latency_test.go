@@ -0,0 +1,24 @@
+package hedge
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatenciesDelay(t *testing.T) {
+	l := newLatencies(100, 32, 0.9, time.Second)
+
+	for i := range 16 {
+		l.Record(time.Duration(i+1) * time.Millisecond)
+	}
+	if want, got := time.Second, l.Delay(); want != got {
+		t.Errorf("unexpected delay before min samples: want=%v, got=%v", want, got)
+	}
+
+	for i := range 16 {
+		l.Record(time.Duration(i+17) * time.Millisecond)
+	}
+	if want, got := 28*time.Millisecond, l.Delay(); want != got {
+		t.Errorf("unexpected delay: want=%v, got=%v", want, got)
+	}
+}
@@ -0,0 +1,18 @@
+package idempotency
+
+import "context"
+
+type contextKey int
+
+const keyContextKey contextKey = iota
+
+// ContextWithKey returns a copy of ctx carrying the idempotency key.
+func ContextWithKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, keyContextKey, key)
+}
+
+// KeyFromContext returns the idempotency key stored in ctx, if any.
+func KeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(keyContextKey).(string)
+	return key, ok && key != ""
+}
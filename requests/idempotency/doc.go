@@ -0,0 +1,3 @@
+// Package idempotency provides a middleware that makes request handlers idempotent
+// by replaying the first result to duplicate requests sharing the same idempotency key.
+package idempotency
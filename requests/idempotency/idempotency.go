@@ -0,0 +1,163 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+
+	"github.com/mcosta74/hexkit/adapters"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+// The errors implement the StatusCoder interface of the HTTP adapter and the ErrorCoder
+// interface of the NATS adapters.
+var (
+	// ErrMissingKey is returned when the key is required but the context doesn't carry one.
+	// It's reported with http.StatusBadRequest.
+	ErrMissingKey error = &codedError{"idempotency: missing key", 400}
+
+	// ErrInProgress is returned when a request with the same key is still being processed.
+	// It's reported with http.StatusConflict.
+	ErrInProgress error = &codedError{"idempotency: request in progress", 409}
+
+	// ErrKeyReused is returned when a key is reused with a different request payload.
+	// It's reported with http.StatusUnprocessableEntity.
+	ErrKeyReused error = &codedError{"idempotency: key reused with a different payload", 422}
+)
+
+type codedError struct {
+	msg    string
+	status int
+}
+
+func (e *codedError) Error() string {
+	return e.msg
+}
+
+func (e *codedError) StatusCode() int {
+	return e.status
+}
+
+func (e *codedError) ErrorCode() string {
+	return strconv.Itoa(e.status)
+}
+
+// FingerprintFunc computes a digest identifying the request payload.
+type FingerprintFunc[Req any] func(ctx context.Context, req Req) (string, error)
+
+// JSONFingerprint is the default [FingerprintFunc]; it returns the SHA-256 of the JSON encoded request.
+func JSONFingerprint[Req any](_ context.Context, req Req) (string, error) {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Option sets optional parameters for the idempotency middleware.
+type Option[Req any] func(c *config[Req])
+
+type config[Req any] struct {
+	fingerprint  FingerprintFunc[Req]
+	required     bool
+	errorHandler adapters.ErrorHandler
+}
+
+// WithFingerprint sets the function used to detect key reuse with a different payload.
+func WithFingerprint[Req any](f FingerprintFunc[Req]) Option[Req] {
+	return func(c *config[Req]) {
+		c.fingerprint = f
+	}
+}
+
+// WithRequiredKey makes the middleware reject requests without an idempotency key
+// with [ErrMissingKey]. By default such requests are passed through.
+func WithRequiredKey[Req any]() Option[Req] {
+	return func(c *config[Req]) {
+		c.required = true
+	}
+}
+
+// WithErrorHandler sets the handler for store errors happening after the request was handled.
+func WithErrorHandler[Req any](eh adapters.ErrorHandler) Option[Req] {
+	return func(c *config[Req]) {
+		c.errorHandler = eh
+	}
+}
+
+// New returns a [requests.Middleware] which uses the key found in the context (see [ContextWithKey])
+// to execute the wrapped handler at most once per key.
+//
+// Duplicates of a completed request receive the stored response; duplicates of a request
+// still in progress fail with [ErrInProgress], while reusing a key for a different payload
+// fails with [ErrKeyReused]. Failed requests are not recorded so they can be retried;
+// neither are the requests whose handler panics.
+func New[Req, Resp any](store Store[Resp], options ...Option[Req]) requests.Middleware[Req, Resp] {
+	cfg := config[Req]{
+		fingerprint:  JSONFingerprint[Req],
+		errorHandler: adapters.NewNoOpErrorHandler(),
+	}
+	for _, o := range options {
+		o(&cfg)
+	}
+
+	return func(next requests.Handler[Req, Resp]) requests.Handler[Req, Resp] {
+		return requests.HandlerFunc[Req, Resp](func(ctx context.Context, req Req) (Resp, error) {
+			var zero Resp
+
+			key, ok := KeyFromContext(ctx)
+			if !ok {
+				if cfg.required {
+					return zero, ErrMissingKey
+				}
+				return next.Handle(ctx, req)
+			}
+
+			fingerprint, err := cfg.fingerprint(ctx, req)
+			if err != nil {
+				return zero, err
+			}
+
+			rec, reserved, err := store.Reserve(ctx, key, fingerprint)
+			if err != nil {
+				return zero, err
+			}
+			if !reserved {
+				switch {
+				case rec.Fingerprint != fingerprint:
+					return zero, ErrKeyReused
+				case !rec.Completed:
+					return zero, ErrInProgress
+				}
+				return rec.Response, nil
+			}
+
+			release := func() {
+				if err := store.Release(context.WithoutCancel(ctx), key); err != nil {
+					cfg.errorHandler.Handle(ctx, err)
+				}
+			}
+			defer func() {
+				if r := recover(); r != nil {
+					release()
+					panic(r)
+				}
+			}()
+
+			resp, err := next.Handle(ctx, req)
+			if err != nil {
+				release()
+				return resp, err
+			}
+
+			rec = Record[Resp]{Fingerprint: fingerprint, Completed: true, Response: resp}
+			if err := store.Complete(context.WithoutCancel(ctx), key, rec); err != nil {
+				cfg.errorHandler.Handle(ctx, err)
+			}
+			return resp, nil
+		})
+	}
+}
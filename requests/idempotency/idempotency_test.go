@@ -0,0 +1,178 @@
+package idempotency_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mcosta74/hexkit/requests"
+	"github.com/mcosta74/hexkit/requests/idempotency"
+)
+
+func TestIdempotency(t *testing.T) {
+	newHandler := func(calls *atomic.Int32, err error, wait <-chan struct{}) requests.Handler[string, string] {
+		return idempotency.New[string, string](idempotency.NewMemoryStore[string](time.Minute))(
+			requests.HandlerFunc[string, string](func(_ context.Context, req string) (string, error) {
+				calls.Add(1)
+				if wait != nil {
+					<-wait
+				}
+				return "hello " + req, err
+			}),
+		)
+	}
+
+	t.Run("Replay", func(t *testing.T) {
+		var calls atomic.Int32
+		h := newHandler(&calls, nil, nil)
+		ctx := idempotency.ContextWithKey(context.Background(), "k1")
+
+		for range 3 {
+			resp, err := h.Handle(ctx, "world")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, got := "hello world", resp; want != got {
+				t.Errorf("unexpected response: want=%q, got=%q", want, got)
+			}
+		}
+		if want, got := int32(1), calls.Load(); want != got {
+			t.Errorf("unexpected calls: want=%d, got=%d", want, got)
+		}
+	})
+
+	t.Run("No Key", func(t *testing.T) {
+		var calls atomic.Int32
+		h := newHandler(&calls, nil, nil)
+
+		_, _ = h.Handle(context.Background(), "world")
+		_, _ = h.Handle(context.Background(), "world")
+
+		if want, got := int32(2), calls.Load(); want != got {
+			t.Errorf("unexpected calls: want=%d, got=%d", want, got)
+		}
+	})
+
+	t.Run("Required Key", func(t *testing.T) {
+		h := idempotency.New(
+			idempotency.NewMemoryStore[string](0),
+			idempotency.WithRequiredKey[string](),
+		)(requests.HandlerFunc[string, string](func(context.Context, string) (string, error) { return "", nil }))
+
+		if _, err := h.Handle(context.Background(), "world"); !errors.Is(err, idempotency.ErrMissingKey) {
+			t.Errorf("unexpected error: want=%v, got=%v", idempotency.ErrMissingKey, err)
+		}
+	})
+
+	t.Run("Key Reused", func(t *testing.T) {
+		var calls atomic.Int32
+		h := newHandler(&calls, nil, nil)
+		ctx := idempotency.ContextWithKey(context.Background(), "k1")
+
+		_, _ = h.Handle(ctx, "world")
+		if _, err := h.Handle(ctx, "moon"); !errors.Is(err, idempotency.ErrKeyReused) {
+			t.Errorf("unexpected error: want=%v, got=%v", idempotency.ErrKeyReused, err)
+		}
+	})
+
+	t.Run("In Progress", func(t *testing.T) {
+		var calls atomic.Int32
+		wait := make(chan struct{})
+		h := newHandler(&calls, nil, wait)
+		ctx := idempotency.ContextWithKey(context.Background(), "k1")
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = h.Handle(ctx, "world")
+		}()
+
+		for calls.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		if _, err := h.Handle(ctx, "world"); !errors.Is(err, idempotency.ErrInProgress) {
+			t.Errorf("unexpected error: want=%v, got=%v", idempotency.ErrInProgress, err)
+		}
+		close(wait)
+		<-done
+	})
+
+	t.Run("Failure Released", func(t *testing.T) {
+		var calls atomic.Int32
+		h := newHandler(&calls, errors.New("fail"), nil)
+		ctx := idempotency.ContextWithKey(context.Background(), "k1")
+
+		_, _ = h.Handle(ctx, "world")
+		_, _ = h.Handle(ctx, "world")
+
+		if want, got := int32(2), calls.Load(); want != got {
+			t.Errorf("unexpected calls: want=%d, got=%d", want, got)
+		}
+	})
+
+	t.Run("Panic Released", func(t *testing.T) {
+		var calls atomic.Int32
+		h := idempotency.New[string, string](idempotency.NewMemoryStore[string](time.Minute))(
+			requests.HandlerFunc[string, string](func(context.Context, string) (string, error) {
+				if calls.Add(1) == 1 {
+					panic("boom")
+				}
+				return "ok", nil
+			}),
+		)
+		ctx := idempotency.ContextWithKey(context.Background(), "k1")
+
+		func() {
+			defer func() {
+				if r := recover(); r != "boom" {
+					t.Errorf("unexpected panic: %v", r)
+				}
+			}()
+			_, _ = h.Handle(ctx, "world")
+		}()
+
+		if resp, err := h.Handle(ctx, "world"); err != nil || resp != "ok" {
+			t.Errorf("unexpected result: %q, %v", resp, err)
+		}
+	})
+}
+
+func TestErrorCodes(t *testing.T) {
+	for _, tc := range []struct {
+		err    error
+		status int
+	}{
+		{idempotency.ErrMissingKey, 400},
+		{idempotency.ErrInProgress, 409},
+		{idempotency.ErrKeyReused, 422},
+	} {
+		sc, ok := tc.err.(interface{ StatusCode() int })
+		if !ok || sc.StatusCode() != tc.status {
+			t.Errorf("unexpected status code of %v", tc.err)
+		}
+		ec, ok := tc.err.(interface{ ErrorCode() string })
+		if !ok || ec.ErrorCode() != strconv.Itoa(tc.status) {
+			t.Errorf("unexpected error code of %v", tc.err)
+		}
+	}
+}
+
+func TestMemoryStoreExpiration(t *testing.T) {
+	ctx := context.Background()
+	store := idempotency.NewMemoryStore[string](20 * time.Millisecond)
+
+	if _, reserved, _ := store.Reserve(ctx, "k1", "f"); !reserved {
+		t.Fatal("expected key to be reserved")
+	}
+	if _, reserved, _ := store.Reserve(ctx, "k1", "f"); reserved {
+		t.Fatal("expected key to be already reserved")
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if _, reserved, _ := store.Reserve(ctx, "k1", "f"); !reserved {
+		t.Error("expected expired key to be reserved again")
+	}
+}
@@ -0,0 +1,106 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Record is the state of an idempotency key.
+type Record[Resp any] struct {
+	// Fingerprint identifies the payload of the request which first used the key.
+	Fingerprint string
+	// Completed reports whether the first request completed; until then the key is in progress.
+	Completed bool
+	// Response is the response of the first request, once completed.
+	Response Resp
+}
+
+// Store is the storage used by the idempotency middleware.
+type Store[Resp any] interface {
+	// Reserve atomically claims key for a new request with the given fingerprint.
+	// If the key is already claimed, it returns the existing record and reserved is false.
+	Reserve(ctx context.Context, key, fingerprint string) (rec Record[Resp], reserved bool, err error)
+	// Complete stores the record of a reserved key.
+	Complete(ctx context.Context, key string, rec Record[Resp]) error
+	// Release removes the reservation of key so that the request can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+// MemoryStore is an in-memory [Store]. Records expire after the configured TTL.
+type MemoryStore[Resp any] struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	records   map[string]memoryRecord[Resp]
+	lastSweep time.Time
+}
+
+type memoryRecord[Resp any] struct {
+	rec     Record[Resp]
+	expires time.Time
+}
+
+// NewMemoryStore creates an in-memory store keeping the records for ttl.
+// If ttl is zero or negative records never expire.
+func NewMemoryStore[Resp any](ttl time.Duration) *MemoryStore[Resp] {
+	return &MemoryStore[Resp]{
+		ttl:     ttl,
+		records: make(map[string]memoryRecord[Resp]),
+	}
+}
+
+// Reserve implements [Store].
+func (s *MemoryStore[Resp]) Reserve(_ context.Context, key, fingerprint string) (Record[Resp], bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	if r, ok := s.records[key]; ok && (r.expires.IsZero() || now.Before(r.expires)) {
+		return r.rec, false, nil
+	}
+
+	rec := Record[Resp]{Fingerprint: fingerprint}
+	s.records[key] = memoryRecord[Resp]{rec: rec, expires: s.expiration(now)}
+	return rec, true, nil
+}
+
+// Complete implements [Store].
+func (s *MemoryStore[Resp]) Complete(_ context.Context, key string, rec Record[Resp]) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[key] = memoryRecord[Resp]{rec: rec, expires: s.expiration(time.Now())}
+	return nil
+}
+
+// Release implements [Store].
+func (s *MemoryStore[Resp]) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// sweep drops expired records, at most once per TTL.
+func (s *MemoryStore[Resp]) sweep(now time.Time) {
+	if s.ttl <= 0 || now.Sub(s.lastSweep) < s.ttl {
+		return
+	}
+	s.lastSweep = now
+
+	for key, r := range s.records {
+		if !now.Before(r.expires) {
+			delete(s.records, key)
+		}
+	}
+}
+
+func (s *MemoryStore[Resp]) expiration(now time.Time) time.Time {
+	if s.ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(s.ttl)
+}
@@ -0,0 +1,95 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// ErrUnknownOperation is wrapped by the errors returned by a [Router] when no handler
+// is registered for the operation of the request.
+var ErrUnknownOperation = errors.New("unknown operation")
+
+// UnknownOperationError is returned by a [Router] when no handler is registered for Key.
+type UnknownOperationError struct {
+	Key string
+}
+
+func (e *UnknownOperationError) Error() string {
+	return fmt.Sprintf("%s: %q", ErrUnknownOperation, e.Key)
+}
+
+// Unwrap returns ErrUnknownOperation.
+func (e *UnknownOperationError) Unwrap() error {
+	return ErrUnknownOperation
+}
+
+// Discriminator extracts the operation key from a transport request.
+type Discriminator[In any] func(ctx context.Context, in In) (string, error)
+
+// Router dispatches transport requests of type In to differently typed handlers,
+// selected by the key returned by a [Discriminator].
+//
+// Router implements Handler[In, any]: it can be exposed by a single input adapter
+// using a pass-through decoder and an encoder accepting any response.
+type Router[In any] struct {
+	key    Discriminator[In]
+	mu     sync.RWMutex
+	routes map[string]Handler[In, any]
+}
+
+// NewRouter creates a router which selects the handlers by the key returned by key.
+func NewRouter[In any](key Discriminator[In]) *Router[In] {
+	return &Router[In]{
+		key:    key,
+		routes: make(map[string]Handler[In, any]),
+	}
+}
+
+// Route registers h on r for the operation key. The transport request is decoded
+// into the handler request by dec. Registering a key twice replaces the handler.
+func Route[In, Req, Resp any](r *Router[In], key string, h Handler[Req, Resp], dec func(context.Context, In) (Req, error)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.routes[key] = HandlerFunc[In, any](func(ctx context.Context, in In) (any, error) {
+		req, err := dec(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+		return h.Handle(ctx, req)
+	})
+}
+
+// Keys returns the sorted list of registered operation keys.
+func (r *Router[In]) Keys() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]string, 0, len(r.routes))
+	for k := range r.routes {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Handle decodes in and calls the handler registered for its operation key.
+// It returns an [*UnknownOperationError] if no handler is registered for the key.
+func (r *Router[In]) Handle(ctx context.Context, in In) (any, error) {
+	key, err := r.key(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	h, ok := r.routes[key]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, &UnknownOperationError{Key: key}
+	}
+	return h.Handle(ctx, in)
+}
@@ -0,0 +1,62 @@
+package requests_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/mcosta74/hexkit/requests"
+)
+
+type message struct {
+	op   string
+	body string
+}
+
+func TestRouter(t *testing.T) {
+	r := requests.NewRouter(func(_ context.Context, m message) (string, error) { return m.op, nil })
+
+	requests.Route(r, "len",
+		requests.HandlerFunc[string, int](func(_ context.Context, s string) (int, error) { return len(s), nil }),
+		func(_ context.Context, m message) (string, error) { return m.body, nil },
+	)
+	requests.Route(r, "double",
+		requests.HandlerFunc[int, int](func(_ context.Context, n int) (int, error) { return 2 * n, nil }),
+		func(_ context.Context, m message) (int, error) { return strconv.Atoi(m.body) },
+	)
+
+	if want, got := []string{"double", "len"}, r.Keys(); len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("unexpected keys: want=%v, got=%v", want, got)
+	}
+
+	tests := []struct {
+		name     string
+		msg      message
+		wantResp any
+		wantErr  error
+	}{
+		{name: "Len", msg: message{op: "len", body: "hello"}, wantResp: 5},
+		{name: "Double", msg: message{op: "double", body: "21"}, wantResp: 42},
+		{name: "Decode Error", msg: message{op: "double", body: "x"}, wantErr: strconv.ErrSyntax},
+		{name: "Unknown", msg: message{op: "triple", body: "1"}, wantErr: requests.ErrUnknownOperation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := r.Handle(context.Background(), tt.msg)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("unexpected error: want=%v, got=%v", tt.wantErr, err)
+			}
+			if tt.wantErr == nil && resp != tt.wantResp {
+				t.Errorf("unexpected response: want=%v, got=%v", tt.wantResp, resp)
+			}
+		})
+	}
+
+	_, err := r.Handle(context.Background(), message{op: "triple"})
+	var uoe *requests.UnknownOperationError
+	if !errors.As(err, &uoe) || uoe.Key != "triple" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,3 @@
+// Package singleflight provides a middleware that coalesces concurrent identical
+// requests into a single call to the wrapped handler.
+package singleflight
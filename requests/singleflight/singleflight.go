@@ -0,0 +1,80 @@
+package singleflight
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/mcosta74/hexkit/internal/singleflight"
+	"github.com/mcosta74/hexkit/requests"
+)
+
+// KeyFunc derives the key identifying identical requests.
+// If ok is false the request is never coalesced.
+type KeyFunc[Req any] func(ctx context.Context, req Req) (key string, ok bool)
+
+// Stats collects the counters of a singleflight middleware.
+type Stats struct {
+	calls     atomic.Uint64
+	coalesced atomic.Uint64
+}
+
+// Calls returns the number of calls made to the wrapped handler.
+func (s *Stats) Calls() uint64 {
+	return s.calls.Load()
+}
+
+// Coalesced returns the number of requests served by a call started for another request.
+func (s *Stats) Coalesced() uint64 {
+	return s.coalesced.Load()
+}
+
+// Option sets optional parameters for the singleflight middleware.
+type Option func(c *config)
+
+type config struct {
+	stats *Stats
+}
+
+// WithStats sets the counters updated by the middleware.
+func WithStats(stats *Stats) Option {
+	return func(c *config) {
+		c.stats = stats
+	}
+}
+
+// New returns a [requests.Middleware] which makes sure that only one call to the wrapped
+// handler is in-flight for a given key; concurrent requests with the same key wait for it
+// and receive the same response.
+//
+// The shared call is not cancelled when a single caller gives up: that caller returns
+// the context error immediately while the others keep waiting. The shared call is
+// cancelled only when every caller has given up.
+func New[Req, Resp any](key KeyFunc[Req], options ...Option) requests.Middleware[Req, Resp] {
+	cfg := config{
+		stats: &Stats{},
+	}
+	for _, o := range options {
+		o(&cfg)
+	}
+
+	return func(next requests.Handler[Req, Resp]) requests.Handler[Req, Resp] {
+		var group singleflight.Group[Resp]
+
+		return requests.HandlerFunc[Req, Resp](func(ctx context.Context, req Req) (Resp, error) {
+			k, ok := key(ctx, req)
+			if !ok {
+				cfg.stats.calls.Add(1)
+				return next.Handle(ctx, req)
+			}
+
+			res := group.Do(ctx, k, func(ctx context.Context) (Resp, error) {
+				cfg.stats.calls.Add(1)
+				return next.Handle(ctx, req)
+			})
+			if res.Joined {
+				cfg.stats.coalesced.Add(1)
+			}
+			return res.Val, res.Err
+		})
+	}
+}
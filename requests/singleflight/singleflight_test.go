@@ -0,0 +1,106 @@
+package singleflight_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mcosta74/hexkit/requests"
+	"github.com/mcosta74/hexkit/requests/singleflight"
+)
+
+func identityKey(_ context.Context, req string) (string, bool) {
+	return req, true
+}
+
+func TestSingleflight(t *testing.T) {
+	t.Run("Coalesce", func(t *testing.T) {
+		var stats singleflight.Stats
+		release := make(chan struct{})
+
+		h := singleflight.New[string, string](identityKey, singleflight.WithStats(&stats))(
+			requests.HandlerFunc[string, string](func(_ context.Context, req string) (string, error) {
+				<-release
+				return "hello " + req, nil
+			}),
+		)
+
+		var wg sync.WaitGroup
+		for range 5 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if resp, _ := h.Handle(context.Background(), "world"); resp != "hello world" {
+					t.Errorf("unexpected response: want=%q, got=%q", "hello world", resp)
+				}
+			}()
+		}
+
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		if want, got := uint64(1), stats.Calls(); want != got {
+			t.Errorf("unexpected calls: want=%d, got=%d", want, got)
+		}
+		if want, got := uint64(4), stats.Coalesced(); want != got {
+			t.Errorf("unexpected coalesced: want=%d, got=%d", want, got)
+		}
+	})
+
+	t.Run("Caller Cancel", func(t *testing.T) {
+		release := make(chan struct{})
+		started := make(chan struct{})
+
+		h := singleflight.New[string, string](identityKey)(
+			requests.HandlerFunc[string, string](func(ctx context.Context, req string) (string, error) {
+				close(started)
+				<-release
+				return "hello " + req, ctx.Err()
+			}),
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		first := make(chan error)
+		go func() {
+			_, err := h.Handle(ctx, "world")
+			first <- err
+		}()
+		<-started
+
+		second := make(chan string)
+		go func() {
+			resp, _ := h.Handle(context.Background(), "world")
+			second <- resp
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		cancel()
+		if err := <-first; !errors.Is(err, context.Canceled) {
+			t.Errorf("unexpected error: want=%v, got=%v", context.Canceled, err)
+		}
+
+		close(release)
+		if want, got := "hello world", <-second; want != got {
+			t.Errorf("unexpected response: want=%q, got=%q", want, got)
+		}
+	})
+
+	t.Run("Bypass", func(t *testing.T) {
+		var stats singleflight.Stats
+
+		h := singleflight.New[string, string](
+			func(context.Context, string) (string, bool) { return "", false },
+			singleflight.WithStats(&stats),
+		)(requests.HandlerFunc[string, string](func(context.Context, string) (string, error) { return "", nil }))
+
+		_, _ = h.Handle(context.Background(), "a")
+		_, _ = h.Handle(context.Background(), "a")
+
+		if want, got := uint64(2), stats.Calls(); want != got {
+			t.Errorf("unexpected calls: want=%d, got=%d", want, got)
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package requests
+
+import (
+	"context"
+	"iter"
+)
+
+// ServerStreamHandler handles a request producing a stream of responses.
+//
+// Streams are iterators of values and errors. Consumers pull the values at their own
+// pace, which provides back-pressure to the producers; a producer stops when the consumer
+// stops iterating or when the context of the handler is cancelled. An error yielded by
+// a stream ends it.
+type ServerStreamHandler[Req, Resp any] interface {
+	HandleStream(ctx context.Context, req Req) iter.Seq2[Resp, error]
+}
+
+// ServerStreamHandlerFunc is an adapter to allow use ordinary function as server streaming handlers.
+type ServerStreamHandlerFunc[Req, Resp any] func(context.Context, Req) iter.Seq2[Resp, error]
+
+// HandleStream calls f(ctx, req)
+func (f ServerStreamHandlerFunc[Req, Resp]) HandleStream(ctx context.Context, req Req) iter.Seq2[Resp, error] {
+	return f(ctx, req)
+}
+
+// ClientStreamHandler handles a stream of requests producing a single response.
+type ClientStreamHandler[Req, Resp any] interface {
+	HandleClientStream(ctx context.Context, reqs iter.Seq2[Req, error]) (Resp, error)
+}
+
+// ClientStreamHandlerFunc is an adapter to allow use ordinary function as client streaming handlers.
+type ClientStreamHandlerFunc[Req, Resp any] func(context.Context, iter.Seq2[Req, error]) (Resp, error)
+
+// HandleClientStream calls f(ctx, reqs)
+func (f ClientStreamHandlerFunc[Req, Resp]) HandleClientStream(ctx context.Context, reqs iter.Seq2[Req, error]) (Resp, error) {
+	return f(ctx, reqs)
+}
+
+// BidiStreamHandler handles a stream of requests producing a stream of responses.
+type BidiStreamHandler[Req, Resp any] interface {
+	HandleBidiStream(ctx context.Context, reqs iter.Seq2[Req, error]) iter.Seq2[Resp, error]
+}
+
+// BidiStreamHandlerFunc is an adapter to allow use ordinary function as bidirectional streaming handlers.
+type BidiStreamHandlerFunc[Req, Resp any] func(context.Context, iter.Seq2[Req, error]) iter.Seq2[Resp, error]
+
+// HandleBidiStream calls f(ctx, reqs)
+func (f BidiStreamHandlerFunc[Req, Resp]) HandleBidiStream(ctx context.Context, reqs iter.Seq2[Req, error]) iter.Seq2[Resp, error] {
+	return f(ctx, reqs)
+}
+
+// ServerStreamMiddleware is a chainable behaviour modifier of the [ServerStreamHandler]
+type ServerStreamMiddleware[Req, Resp any] func(ServerStreamHandler[Req, Resp]) ServerStreamHandler[Req, Resp]
+
+// ClientStreamMiddleware is a chainable behaviour modifier of the [ClientStreamHandler]
+type ClientStreamMiddleware[Req, Resp any] func(ClientStreamHandler[Req, Resp]) ClientStreamHandler[Req, Resp]
+
+// BidiStreamMiddleware is a chainable behaviour modifier of the [BidiStreamHandler]
+type BidiStreamMiddleware[Req, Resp any] func(BidiStreamHandler[Req, Resp]) BidiStreamHandler[Req, Resp]
+
+// ChainServerStream composes server streaming middlewares like [Chain].
+func ChainServerStream[Req, Resp any](outer ServerStreamMiddleware[Req, Resp], others ...ServerStreamMiddleware[Req, Resp]) ServerStreamMiddleware[Req, Resp] {
+	return chain(outer, others)
+}
+
+// ChainClientStream composes client streaming middlewares like [Chain].
+func ChainClientStream[Req, Resp any](outer ClientStreamMiddleware[Req, Resp], others ...ClientStreamMiddleware[Req, Resp]) ClientStreamMiddleware[Req, Resp] {
+	return chain(outer, others)
+}
+
+// ChainBidiStream composes bidirectional streaming middlewares like [Chain].
+func ChainBidiStream[Req, Resp any](outer BidiStreamMiddleware[Req, Resp], others ...BidiStreamMiddleware[Req, Resp]) BidiStreamMiddleware[Req, Resp] {
+	return chain(outer, others)
+}
+
+// ChannelStream returns a stream yielding the values received from ch until it's closed
+// or ctx is done, in which case it yields the error of ctx.
+func ChannelStream[T any](ctx context.Context, ch <-chan T) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			select {
+			case v, ok := <-ch:
+				if !ok || !yield(v, nil) {
+					return
+				}
+			case <-ctx.Done():
+				var zero T
+				yield(zero, ctx.Err())
+				return
+			}
+		}
+	}
+}
+
+// StreamOf returns a stream yielding values.
+func StreamOf[T any](values ...T) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for _, v := range values {
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package requests_test
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mcosta74/hexkit/requests"
+)
+
+// collect returns the values of seq up to the first error.
+func collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	var values []T
+	for v, err := range seq {
+		if err != nil {
+			return values, err
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+func TestChainServerStream(t *testing.T) {
+	// prefix prepends s to the responses of the stream.
+	prefix := func(s string) requests.ServerStreamMiddleware[int, string] {
+		return func(next requests.ServerStreamHandler[int, string]) requests.ServerStreamHandler[int, string] {
+			return requests.ServerStreamHandlerFunc[int, string](func(ctx context.Context, req int) iter.Seq2[string, error] {
+				return func(yield func(string, error) bool) {
+					for resp, err := range next.HandleStream(ctx, req) {
+						if !yield(s+resp, err) {
+							return
+						}
+					}
+				}
+			})
+		}
+	}
+
+	h := requests.ChainServerStream(prefix("a"), prefix("b"))(
+		requests.ServerStreamHandlerFunc[int, string](func(_ context.Context, n int) iter.Seq2[string, error] {
+			return requests.StreamOf(slices.Repeat([]string{"x"}, n)...)
+		}),
+	)
+
+	got, err := collect(h.HandleStream(context.Background(), 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"abx", "abx"}; !slices.Equal(want, got) {
+		t.Errorf("unexpected responses: want=%v, got=%v", want, got)
+	}
+}
+
+func TestChainClientStream(t *testing.T) {
+	var calls []string
+	trace := func(s string) requests.ClientStreamMiddleware[string, string] {
+		return func(next requests.ClientStreamHandler[string, string]) requests.ClientStreamHandler[string, string] {
+			return requests.ClientStreamHandlerFunc[string, string](func(ctx context.Context, reqs iter.Seq2[string, error]) (string, error) {
+				calls = append(calls, s)
+				return next.HandleClientStream(ctx, reqs)
+			})
+		}
+	}
+
+	join := requests.ClientStreamHandlerFunc[string, string](func(_ context.Context, reqs iter.Seq2[string, error]) (string, error) {
+		values, err := collect(reqs)
+		return strings.Join(values, ","), err
+	})
+
+	got, err := requests.ChainClientStream(trace("outer"), trace("inner"))(join).
+		HandleClientStream(context.Background(), requests.StreamOf("a", "b", "c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a,b,c"; want != got {
+		t.Errorf("unexpected response: want=%q, got=%q", want, got)
+	}
+	if want := []string{"outer", "inner"}; !slices.Equal(want, calls) {
+		t.Errorf("unexpected calls: want=%v, got=%v", want, calls)
+	}
+}
+
+func TestChainBidiStream(t *testing.T) {
+	upper := requests.BidiStreamHandlerFunc[string, string](func(_ context.Context, reqs iter.Seq2[string, error]) iter.Seq2[string, error] {
+		return func(yield func(string, error) bool) {
+			for req, err := range reqs {
+				if !yield(strings.ToUpper(req), err) || err != nil {
+					return
+				}
+			}
+		}
+	})
+	noop := func(next requests.BidiStreamHandler[string, string]) requests.BidiStreamHandler[string, string] {
+		return next
+	}
+
+	errBroken := errors.New("broken")
+	reqs := func(yield func(string, error) bool) {
+		_ = yield("a", nil) && yield("", errBroken) && yield("b", nil)
+	}
+
+	got, err := collect(requests.ChainBidiStream(noop)(upper).HandleBidiStream(context.Background(), reqs))
+	if !errors.Is(err, errBroken) {
+		t.Errorf("unexpected error: want=%v, got=%v", errBroken, err)
+	}
+	if want := []string{"A"}; !slices.Equal(want, got) {
+		t.Errorf("unexpected responses: want=%v, got=%v", want, got)
+	}
+}
+
+func TestChannelStream(t *testing.T) {
+	t.Run("Closed", func(t *testing.T) {
+		ch := make(chan int, 2)
+		ch <- 1
+		ch <- 2
+		close(ch)
+
+		got, err := collect(requests.ChannelStream(context.Background(), ch))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []int{1, 2}; !slices.Equal(want, got) {
+			t.Errorf("unexpected values: want=%v, got=%v", want, got)
+		}
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := collect(requests.ChannelStream(ctx, make(chan int)))
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("unexpected error: want=%v, got=%v", context.Canceled, err)
+		}
+	})
+}